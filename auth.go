@@ -0,0 +1,249 @@
+package rip
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// defaultTokenLeeway is how long before its expiry a token is refreshed.
+const defaultTokenLeeway = 10 * time.Second
+
+// ErrNoToken occurs when a TokenSource returns no access token.
+var ErrNoToken = errors.New("token source returned no access token")
+
+// Authenticator adds credentials to requests. It is invoked for every
+// request before it is executed, retries reuse the credentials.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// Invalidator is implemented by authenticators with renewable credentials.
+// A request rejected with 401 Unauthorized is retried once after the
+// credentials it was sent with were invalidated.
+type Invalidator interface {
+	Invalidate(req *http.Request)
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator.
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate calls f.
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error { return f(req) }
+
+// WithAuth authenticates all requests of the client.
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// SetAuth overrides the authenticator of the client for this request.
+func (r *Request) SetAuth(auth Authenticator) *Request {
+	r.auth = auth
+
+	return r
+}
+
+// BasicAuth authenticates with username and password, see RFC 7617.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Authenticate sets the Authorization header.
+func (a BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+
+	return nil
+}
+
+// BearerAuth authenticates with a static bearer token, see RFC 6750.
+type BearerAuth struct {
+	Token string
+}
+
+// Authenticate sets the Authorization header.
+func (a BearerAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+
+	return nil
+}
+
+// APIKeyLocation is where an API key is sent.
+type APIKeyLocation int
+
+const (
+	// APIKeyHeader sends the API key as header.
+	APIKeyHeader APIKeyLocation = iota
+	// APIKeyQuery sends the API key as query parameter.
+	APIKeyQuery
+)
+
+// APIKeyAuth authenticates with an API key in a header or query parameter.
+type APIKeyAuth struct {
+	Name  string
+	Value string
+	In    APIKeyLocation
+}
+
+// Authenticate sets the header or query parameter.
+func (a APIKeyAuth) Authenticate(req *http.Request) error {
+	switch a.In {
+	case APIKeyQuery:
+		q := req.URL.Query()
+		q.Set(a.Name, a.Value)
+		req.URL.RawQuery = q.Encode()
+	case APIKeyHeader:
+		req.Header.Set(a.Name, a.Value)
+	}
+
+	return nil
+}
+
+// Token is an access token.
+type Token struct {
+	AccessToken string
+	// TokenType defaults to Bearer.
+	TokenType string
+	// Expiry is when the token expires, zero if it does not.
+	Expiry time.Time
+}
+
+// valid reports whether the token can be used for leeway longer.
+func (t *Token) valid(leeway time.Duration) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+
+	return t.Expiry.IsZero() || time.Now().Add(leeway).Before(t.Expiry)
+}
+
+func (t *Token) header() string {
+	return cmp.Or(t.TokenType, "Bearer") + " " + t.AccessToken
+}
+
+// TokenSource fetches access tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token calls f.
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) { return f(ctx) }
+
+// TokenAuth authenticates with tokens of a TokenSource. A token is cached
+// until it expires and refreshed by a single caller, while concurrent
+// requests wait for the new token.
+type TokenAuth struct {
+	source TokenSource
+	leeway time.Duration
+	// sem is held while fetching a token, unlike a mutex
+	// waiting for it respects the request context.
+	sem   chan struct{}
+	token *Token
+}
+
+// NewTokenAuth creates a TokenAuth refreshing tokens leeway before they
+// expire. leeway defaults to 10s.
+func NewTokenAuth(source TokenSource, leeway time.Duration) *TokenAuth {
+	if leeway <= 0 {
+		leeway = defaultTokenLeeway
+	}
+
+	return &TokenAuth{source: source, leeway: leeway, sem: make(chan struct{}, 1)}
+}
+
+// Authenticate sets the Authorization header, fetching a token if needed.
+func (a *TokenAuth) Authenticate(req *http.Request) error {
+	t, err := a.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", t.header())
+
+	return nil
+}
+
+// Token returns the cached token or fetches a new one.
+func (a *TokenAuth) Token(ctx context.Context) (*Token, error) {
+	select {
+	case a.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-a.sem }()
+
+	if a.token.valid(a.leeway) {
+		return a.token, nil
+	}
+
+	t, err := a.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if t == nil || t.AccessToken == "" {
+		return nil, ErrNoToken
+	}
+
+	a.token = t
+
+	return t, nil
+}
+
+// Invalidate drops the cached token, if req was sent with it. Requests
+// rejected with an already replaced token do not cause another refresh.
+func (a *TokenAuth) Invalidate(req *http.Request) {
+	a.sem <- struct{}{}
+	defer func() { <-a.sem }()
+
+	if a.token != nil && req.Header.Get("Authorization") == a.token.header() {
+		a.token = nil
+	}
+}
+
+// authenticator returns the authenticator of the request or client.
+func (r *Request) authenticator() Authenticator {
+	if r.auth != nil {
+		return r.auth
+	}
+
+	return r.client.auth
+}
+
+// authHandler wraps next, authenticating the request and retrying it once
+// with renewed credentials if it is rejected with 401 Unauthorized.
+func authHandler(next Handler) Handler {
+	return func(req *Request) (*Response, error) {
+		auth := req.authenticator()
+		if auth == nil {
+			return next(req)
+		}
+
+		if err := auth.Authenticate(req.rawRequest); err != nil {
+			return NewResponse(req, nil), err
+		}
+
+		res, err := next(req)
+
+		inv, ok := auth.(Invalidator)
+		if err != nil || res.StatusCode() != http.StatusUnauthorized || !ok || !req.rewindable() {
+			return res, err
+		}
+
+		inv.Invalidate(req.rawRequest)
+		res.discard()
+
+		if err := auth.Authenticate(req.rawRequest); err != nil {
+			return NewResponse(req, nil), err
+		}
+
+		return next(req)
+	}
+}
@@ -0,0 +1,326 @@
+package rip
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func setupAuthServer(hits *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+
+		if r.URL.Path == "/protected" && r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Path == "/shared" {
+			w.Header().Set("Cache-Control", "max-age=60")
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		fmt.Fprintf(w, "%s|%s|%s", r.Header.Get("Authorization"), r.Header.Get("X-Api-Key"), r.URL.RawQuery)
+	}))
+}
+
+func TestAuthenticators(t *testing.T) {
+	type tcase struct {
+		auth     Authenticator
+		expected string
+	}
+
+	tests := map[string]tcase{
+		"basic": {
+			auth:     BasicAuth{Username: "user", Password: "pass"},
+			expected: "Basic dXNlcjpwYXNz||",
+		},
+		"bearer": {
+			auth:     BearerAuth{Token: "token"},
+			expected: "Bearer token||",
+		},
+		"api key header": {
+			auth:     APIKeyAuth{Name: "X-Api-Key", Value: "key"},
+			expected: "|key|",
+		},
+		"api key query": {
+			auth:     APIKeyAuth{Name: "api_key", Value: "key", In: APIKeyQuery},
+			expected: "||api_key=key",
+		},
+		"func": {
+			auth: AuthenticatorFunc(func(req *http.Request) error {
+				req.Header.Set("Authorization", "Custom")
+				return nil
+			}),
+			expected: "Custom||",
+		},
+	}
+
+	var hits atomic.Int32
+
+	server := setupAuthServer(&hits)
+	defer server.Close()
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			c, err := NewClient(server.URL, WithAuth(tc.auth))
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			req := c.NR()
+
+			res, err := req.Execute(t.Context(), http.MethodGet, "/")
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+			defer res.Close()
+
+			if got := res.String(); got != tc.expected {
+				t.Errorf("expected: %q, got: %q", tc.expected, got)
+			}
+
+			if len(req.Header) != 0 {
+				t.Errorf("expected credentials not to leak into the request header, got: %v", req.Header)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestRequestAuthOverride(t *testing.T) {
+	var hits atomic.Int32
+
+	server := setupAuthServer(&hits)
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithAuth(BearerAuth{Token: "client"}))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	res, err := c.NR().SetAuth(BearerAuth{Token: "request"}).Execute(t.Context(), http.MethodGet, "/")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if got := res.String(); got != "Bearer request||" {
+		t.Errorf("expected request authenticator, got: %q", got)
+	}
+}
+
+func TestAuthSharedResponses(t *testing.T) {
+	type tcase struct {
+		option     Option
+		concurrent bool
+	}
+
+	tests := map[string]tcase{
+		"cache": {
+			option: WithCache(NewMemoryStore(10)),
+		},
+		"coalescing": {
+			option:     WithCoalescing(),
+			concurrent: true,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var hits atomic.Int32
+
+			server := setupAuthServer(&hits)
+			defer server.Close()
+
+			c, err := NewClient(server.URL, tc.option)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			get := func(user string) {
+				res, err := c.NR().SetAuth(BearerAuth{Token: user}).Execute(t.Context(), http.MethodGet, "/shared")
+				if err != nil {
+					t.Errorf("expected err to be nil, got: %v", err)
+					return
+				}
+				defer res.Close() //nolint: errcheck
+
+				if got := res.String(); got != "Bearer "+user+"||" {
+					t.Errorf("expected response for %s, got: %q", user, got)
+				}
+			}
+
+			var wg sync.WaitGroup
+
+			for _, user := range []string{"alice", "bob", "carol", "bob"} {
+				if !tc.concurrent {
+					get(user)
+					continue
+				}
+
+				wg.Go(func() { get(user) })
+			}
+
+			wg.Wait()
+
+			if !tc.concurrent && hits.Load() != 3 {
+				t.Errorf("expected repeated request to be served from cache, got %d requests", hits.Load())
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	type tcase struct {
+		tokens     []*Token
+		path       string
+		requests   int
+		expFetches int32
+		expHits    int32
+		expStatus  int
+	}
+
+	valid := time.Now().Add(time.Hour)
+
+	tests := map[string]tcase{
+		"token is cached": {
+			tokens:     []*Token{{AccessToken: "fresh", Expiry: valid}},
+			requests:   3,
+			expFetches: 1,
+			expHits:    3,
+			expStatus:  http.StatusOK,
+		},
+		"expiring token is refreshed": {
+			tokens: []*Token{
+				{AccessToken: "expiring", Expiry: time.Now().Add(time.Second)},
+				{AccessToken: "fresh", Expiry: valid},
+			},
+			requests:   2,
+			expFetches: 2,
+			expHits:    2,
+			expStatus:  http.StatusOK,
+		},
+		"rejected token is refreshed once": {
+			tokens: []*Token{
+				{AccessToken: "revoked", Expiry: valid},
+				{AccessToken: "fresh", Expiry: valid},
+			},
+			path:       "/protected",
+			requests:   1,
+			expFetches: 2,
+			expHits:    2,
+			expStatus:  http.StatusOK,
+		},
+		"rejected fresh token is not retried again": {
+			tokens: []*Token{
+				{AccessToken: "revoked", Expiry: valid},
+				{AccessToken: "revoked", Expiry: valid},
+			},
+			path:       "/protected",
+			requests:   1,
+			expFetches: 2,
+			expHits:    2,
+			expStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var hits, fetches atomic.Int32
+
+			server := setupAuthServer(&hits)
+			defer server.Close()
+
+			source := TokenSourceFunc(func(context.Context) (*Token, error) {
+				n := fetches.Add(1)
+				return tc.tokens[min(int(n), len(tc.tokens))-1], nil
+			})
+
+			c, err := NewClient(server.URL, WithAuth(NewTokenAuth(source, 10*time.Second)))
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			var res *Response
+			for range tc.requests {
+				res, err = c.NR().Execute(t.Context(), http.MethodGet, tc.path)
+				if err != nil {
+					t.Fatalf("expected err to be nil, got: %v", err)
+				}
+
+				res.discard()
+			}
+
+			if res.StatusCode() != tc.expStatus {
+				t.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode())
+			}
+
+			if got := fetches.Load(); got != tc.expFetches {
+				t.Errorf("expected %d token fetches, got: %d", tc.expFetches, got)
+			}
+
+			if got := hits.Load(); got != tc.expHits {
+				t.Errorf("expected %d requests, got: %d", tc.expHits, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestTokenAuthConcurrentRefresh(t *testing.T) {
+	var hits, fetches atomic.Int32
+
+	server := setupAuthServer(&hits)
+	defer server.Close()
+
+	source := TokenSourceFunc(func(context.Context) (*Token, error) {
+		fetches.Add(1)
+		time.Sleep(50 * time.Millisecond)
+
+		return &Token{AccessToken: "fresh"}, nil
+	})
+
+	c, err := NewClient(server.URL, WithAuth(NewTokenAuth(source, 0)))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Go(func() {
+			res, err := c.NR().Execute(t.Context(), http.MethodGet, "/")
+			if err != nil {
+				t.Errorf("expected err to be nil, got: %v", err)
+				return
+			}
+
+			res.discard()
+		})
+	}
+
+	wg.Wait()
+
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("expected a single token fetch, got: %d", got)
+	}
+}
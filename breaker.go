@@ -0,0 +1,245 @@
+package rip
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenTimeout      = 30 * time.Second
+	defaultBreakerHalfOpenRequests = 1
+)
+
+// ErrCircuitOpen occurs when a request is rejected by an open circuit breaker.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests pass.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests pass.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// FailureFunc decides whether an attempt counts as failure.
+type FailureFunc func(res *Response, err error, latency time.Duration) bool
+
+// CircuitBreakerSettings configures a CircuitBreaker.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures
+	// opening the circuit. Defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before
+	// letting probes pass. Defaults to 30s.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of probes allowed while half-open.
+	// All of them have to succeed to close the circuit. Defaults to 1.
+	HalfOpenRequests int
+	// LatencyThreshold counts attempts slower than this as failure.
+	// Zero disables the check.
+	LatencyThreshold time.Duration
+	// IsFailure overrides the default failure criteria of transport
+	// errors, 5xx responses and LatencyThreshold. Attempts canceled by
+	// the caller count as neither failure nor success.
+	IsFailure FailureFunc
+	// OnStateChange is called on every state transition.
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitBreaker stops requests to a failing upstream for a while.
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	settings CircuitBreakerSettings
+	state    CircuitState
+	failures int
+	probes   int
+	passed   int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker.
+// Zero values in settings are replaced by defaults.
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.FailureThreshold < 1 {
+		settings.FailureThreshold = defaultBreakerFailureThreshold
+	}
+
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = defaultBreakerOpenTimeout
+	}
+
+	if settings.HalfOpenRequests < 1 {
+		settings.HalfOpenRequests = defaultBreakerHalfOpenRequests
+	}
+
+	if settings.IsFailure == nil {
+		threshold := settings.LatencyThreshold
+		settings.IsFailure = func(res *Response, err error, latency time.Duration) bool {
+			return defaultIsFailure(res, err, latency, threshold)
+		}
+	}
+
+	return &CircuitBreaker{settings: settings}
+}
+
+// WithCircuitBreaker guards all requests of the client with a circuit breaker.
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	return func(c *Client) {
+		c.breaker = NewCircuitBreaker(settings)
+	}
+}
+
+// CircuitBreaker returns the circuit breaker of the client or nil if none is set.
+func (c *Client) CircuitBreaker() *CircuitBreaker {
+	return c.breaker
+}
+
+func defaultIsFailure(res *Response, err error, latency, threshold time.Duration) bool {
+	if err != nil {
+		return true
+	}
+
+	if threshold > 0 && latency > threshold {
+		return true
+	}
+
+	return res.StatusCode() >= http.StatusInternalServerError
+}
+
+// State returns the current state.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.settings.OpenTimeout {
+		return CircuitHalfOpen
+	}
+
+	return b.state
+}
+
+// allow reports whether a request may pass, returning ErrCircuitOpen if not.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	from := b.state
+	err := b.admit()
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+
+	return err
+}
+
+// admit implements allow. b.mu must be held.
+func (b *CircuitBreaker) admit() error {
+	if b.state == CircuitOpen {
+		if time.Since(b.openedAt) < b.settings.OpenTimeout {
+			return ErrCircuitOpen
+		}
+
+		b.transition(CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.probes >= b.settings.HalfOpenRequests {
+			return ErrCircuitOpen
+		}
+
+		b.probes++
+	}
+
+	return nil
+}
+
+// record feeds the outcome of an attempt into the breaker.
+func (b *CircuitBreaker) record(res *Response, err error, latency time.Duration) {
+	if errors.Is(err, context.Canceled) {
+		b.release()
+		return
+	}
+
+	failed := b.settings.IsFailure(res, err, latency)
+
+	b.mu.Lock()
+	from := b.state
+
+	switch b.state {
+	case CircuitClosed:
+		if !failed {
+			b.failures = 0
+			break
+		}
+
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.transition(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		if failed {
+			b.transition(CircuitOpen)
+			break
+		}
+
+		b.passed++
+		if b.passed >= b.settings.HalfOpenRequests {
+			b.transition(CircuitClosed)
+		}
+	case CircuitOpen:
+		// late result of a request started before the circuit opened.
+	}
+
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+// release frees the slot of a half-open probe without an outcome.
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// transition moves to state and resets counters. b.mu must be held.
+func (b *CircuitBreaker) transition(state CircuitState) {
+	b.state = state
+	b.failures = 0
+	b.probes = 0
+	b.passed = 0
+
+	if state == CircuitOpen {
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *CircuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(from, to)
+	}
+}
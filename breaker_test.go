@@ -0,0 +1,161 @@
+package rip
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		calls   atomic.Int32
+		healthy atomic.Bool
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var (
+		mu          sync.Mutex
+		transitions []string
+	)
+
+	c, err := NewClient(server.URL, WithCircuitBreaker(CircuitBreakerSettings{
+		FailureThreshold: 2,
+		OpenTimeout:      50 * time.Millisecond,
+		OnStateChange: func(from, to CircuitState) {
+			mu.Lock()
+			defer mu.Unlock()
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	}))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	execute := func() error {
+		res, err := c.NR().Execute(t.Context(), http.MethodGet, "/")
+		res.Close()
+		return err
+	}
+
+	for range 2 {
+		if err := execute(); err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+	}
+
+	if state := c.CircuitBreaker().State(); state != CircuitOpen {
+		t.Fatalf("expected circuit to be open, got: %s", state)
+	}
+
+	if err := execute(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got: %v", err)
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected open circuit not to reach upstream, got %d calls", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	healthy.Store(true)
+
+	if err := execute(); err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+
+	if state := c.CircuitBreaker().State(); state != CircuitClosed {
+		t.Fatalf("expected circuit to be closed, got: %s", state)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(expected) {
+		t.Fatalf("expected transitions %v, got: %v", expected, transitions)
+	}
+
+	for i, exp := range expected {
+		if transitions[i] != exp {
+			t.Errorf("expected transition %s, got: %s", exp, transitions[i])
+		}
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerSettings{
+		FailureThreshold: 1,
+		OpenTimeout:      10 * time.Millisecond,
+		LatencyThreshold: time.Second,
+	})
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	b.record(NewResponse(nil, &http.Response{StatusCode: http.StatusOK}), nil, 2*time.Second)
+
+	if state := b.State(); state != CircuitOpen {
+		t.Fatalf("expected slow response to open circuit, got: %s", state)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected probe to pass, got: %v", err)
+	}
+
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected second probe to be rejected, got: %v", err)
+	}
+
+	b.record(NewResponse(nil, nil), errors.New("connection refused"), 0)
+
+	if state := b.State(); state != CircuitOpen {
+		t.Fatalf("expected failed probe to reopen circuit, got: %s", state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenCanceled(t *testing.T) {
+	b := NewCircuitBreaker(CircuitBreakerSettings{
+		FailureThreshold: 1,
+		OpenTimeout:      10 * time.Millisecond,
+	})
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	b.record(NewResponse(nil, nil), errors.New("connection refused"), 0)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected probe to pass, got: %v", err)
+	}
+	b.record(NewResponse(nil, nil), context.Canceled, 0)
+
+	if state := b.State(); state != CircuitHalfOpen {
+		t.Fatalf("expected canceled probe to keep circuit half-open, got: %s", state)
+	}
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected canceled probe to free its slot, got: %v", err)
+	}
+	b.record(NewResponse(nil, &http.Response{StatusCode: http.StatusOK}), nil, 0)
+
+	if state := b.State(); state != CircuitClosed {
+		t.Fatalf("expected successful probe to close circuit, got: %s", state)
+	}
+}
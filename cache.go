@@ -0,0 +1,394 @@
+package rip
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// heuristicFraction of the time since Last-Modified a response without
+// explicit expiration is considered fresh, see RFC 9111 4.2.2.
+const heuristicFraction = 10
+
+// CacheEntry is a response stored by a CacheStore.
+type CacheEntry struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+	// Vary holds the request headers selected by the Vary response header.
+	Vary http.Header
+	// Stored is when the response was received, corrected by its Age header.
+	Stored time.Time
+}
+
+// CacheStore stores cache entries by key. Implementations have to be safe
+// for concurrent use. Storage errors are treated as cache misses. Entries
+// are not modified once stored, so that they can be shared by callers.
+type CacheStore interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+}
+
+// WithCache caches GET and HEAD responses in store following the HTTP
+// caching semantics of RFC 9111. Fresh responses are served from the store,
+// stale ones are revalidated using ETag and Last-Modified, and served
+// despite errors within their stale-if-error window. Responses are cached
+// separately for each Authorization and Cookie header.
+func WithCache(store CacheStore) Option {
+	return func(c *Client) {
+		c.cache = &httpCache{store: store, now: time.Now}
+	}
+}
+
+type httpCache struct {
+	store CacheStore
+	now   func() time.Time
+}
+
+// handler wraps next with the cache.
+func (hc *httpCache) handler(next Handler) Handler {
+	return func(req *Request) (*Response, error) {
+		raw := req.rawRequest
+
+		if raw.Method != http.MethodGet && raw.Method != http.MethodHead {
+			res, err := next(req)
+			if err == nil && !res.IsError() {
+				// unsafe methods invalidate the cached target, see RFC 9111 4.4.
+				hc.store.Delete(cacheKey(http.MethodGet, raw))
+				hc.store.Delete(cacheKey(http.MethodHead, raw))
+			}
+
+			return res, err
+		}
+
+		reqCC := parseCacheControl(raw.Header)
+		if _, ok := reqCC["no-store"]; ok || req.streaming() {
+			return next(req)
+		}
+
+		key := cacheKey(raw.Method, raw)
+
+		entry, ok := hc.store.Get(key)
+		if ok && !entry.matches(raw.Header) {
+			entry, ok = nil, false
+		}
+
+		if ok && hc.fresh(entry, reqCC) {
+			return hc.response(req, entry), nil
+		}
+
+		vary := raw.Header
+		if ok {
+			hc.conditional(req, entry)
+		}
+
+		res, err := next(req)
+
+		if ok && hc.staleIfError(entry, reqCC, res, err) {
+			if res != nil {
+				res.discard()
+			}
+
+			return hc.response(req, entry), nil
+		}
+
+		if err != nil {
+			return res, err
+		}
+
+		if ok && res.StatusCode() == http.StatusNotModified {
+			res.discard()
+
+			entry = entry.clone()
+			entry.update(res.Header(), hc.now())
+			hc.store.Set(key, entry)
+
+			return hc.response(req, entry), nil
+		}
+
+		hc.save(key, vary, res)
+
+		return res, nil
+	}
+}
+
+// fresh reports whether entry can be served without revalidation.
+func (hc *httpCache) fresh(entry *CacheEntry, reqCC map[string]string) bool {
+	if _, ok := reqCC["no-cache"]; ok {
+		return false
+	}
+
+	if _, ok := parseCacheControl(entry.Header)["no-cache"]; ok {
+		return false
+	}
+
+	lifetime := entry.lifetime()
+	if v, ok := reqCC["max-age"]; ok {
+		if maxAge, ok := parseSeconds(v); ok {
+			lifetime = min(lifetime, maxAge)
+		}
+	}
+
+	return hc.now().Sub(entry.Stored) < lifetime
+}
+
+// staleIfError reports whether entry may be served instead of a failed
+// revalidation, see RFC 5861 4.
+func (hc *httpCache) staleIfError(entry *CacheEntry, reqCC map[string]string, res *Response, err error) bool {
+	if err == nil && res.StatusCode() < http.StatusInternalServerError {
+		return false
+	}
+
+	resCC := parseCacheControl(entry.Header)
+	if _, ok := resCC["must-revalidate"]; ok {
+		return false
+	}
+
+	v, ok := reqCC["stale-if-error"]
+	if !ok {
+		v, ok = resCC["stale-if-error"]
+	}
+
+	window, valid := parseSeconds(v)
+	if !ok || !valid {
+		return false
+	}
+
+	return hc.now().Sub(entry.Stored) < entry.lifetime()+window
+}
+
+// conditional adds the validators of entry to the request.
+func (hc *httpCache) conditional(req *Request, entry *CacheEntry) {
+	etag := entry.Header.Get("ETag")
+	lastModified := entry.Header.Get("Last-Modified")
+
+	if etag == "" && lastModified == "" {
+		return
+	}
+
+	h := req.rawRequest.Header
+
+	if etag != "" && h.Get("If-None-Match") == "" {
+		h.Set("If-None-Match", etag)
+	}
+
+	if lastModified != "" && h.Get("If-Modified-Since") == "" {
+		h.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// save stores res if it is cacheable. The body is buffered, so that
+// the caller can still read it. Decoded bodies are stored without their
+// Content-Encoding, so that header and body of the entry match.
+func (hc *httpCache) save(key string, reqHeader http.Header, res *Response) {
+	if !cacheable(res) {
+		return
+	}
+
+	body, err := res.BodyE()
+	if err != nil {
+		return
+	}
+
+	entry := &CacheEntry{
+		StatusCode: res.StatusCode(),
+		Status:     res.Status(),
+		Header:     res.Header().Clone(),
+		Body:       body,
+		Vary:       http.Header{},
+	}
+
+	if res.encoded != nil {
+		entry.Header.Del("Content-Encoding")
+		entry.Header.Del("Content-Length")
+	}
+
+	for _, name := range varyHeaders(res.Header()) {
+		if v, ok := reqHeader[http.CanonicalHeaderKey(name)]; ok {
+			entry.Vary[http.CanonicalHeaderKey(name)] = v
+		}
+	}
+
+	entry.update(http.Header{}, hc.now())
+	hc.store.Set(key, entry)
+}
+
+// response creates a Response served from entry.
+func (hc *httpCache) response(req *Request, entry *CacheEntry) *Response {
+	header := entry.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(hc.now().Sub(entry.Stored).Seconds())))
+
+	res := NewResponse(req, &http.Response{
+		Status:        entry.Status,
+		StatusCode:    entry.StatusCode,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req.rawRequest,
+	})
+	res.fromCache = true
+
+	return res
+}
+
+// lifetime returns the freshness lifetime of the entry.
+func (e *CacheEntry) lifetime() time.Duration {
+	if v, ok := parseCacheControl(e.Header)["max-age"]; ok {
+		maxAge, _ := parseSeconds(v)
+		return maxAge
+	}
+
+	date, err := http.ParseTime(e.Header.Get("Date"))
+	if err != nil {
+		date = e.Stored
+	}
+
+	if v := e.Header.Get("Expires"); v != "" {
+		expires, err := http.ParseTime(v)
+		if err != nil {
+			return 0
+		}
+
+		return max(expires.Sub(date), 0)
+	}
+
+	if lastModified, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil {
+		return max(date.Sub(lastModified)/heuristicFraction, 0)
+	}
+
+	return 0
+}
+
+// clone returns a copy of the entry sharing its body.
+func (e *CacheEntry) clone() *CacheEntry {
+	c := *e
+	c.Header = e.Header.Clone()
+	c.Vary = e.Vary.Clone()
+
+	return &c
+}
+
+// update merges header of a revalidation into the entry.
+func (e *CacheEntry) update(header http.Header, now time.Time) {
+	for k, v := range header {
+		if k != "Content-Length" {
+			e.Header[k] = v
+		}
+	}
+
+	age, _ := parseSeconds(e.Header.Get("Age"))
+	e.Header.Del("Age")
+	e.Stored = now.Add(-age)
+}
+
+// matches reports whether the request header selects the entry.
+func (e *CacheEntry) matches(header http.Header) bool {
+	for _, name := range varyHeaders(e.Header) {
+		k := http.CanonicalHeaderKey(name)
+		if strings.Join(header.Values(k), ",") != strings.Join(e.Vary.Values(k), ",") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cacheable reports whether res may be stored.
+func cacheable(res *Response) bool {
+	switch res.StatusCode() {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusNotFound,
+		http.StatusMethodNotAllowed, http.StatusGone, http.StatusRequestURITooLong,
+		http.StatusNotImplemented:
+	default:
+		return false
+	}
+
+	if _, ok := parseCacheControl(res.Header())["no-store"]; ok {
+		return false
+	}
+
+	// event streams do not end and cannot be buffered.
+	if mt, _, _ := mime.ParseMediaType(res.Header().Get("Content-Type")); mt == contentTypeEventStream {
+		return false
+	}
+
+	for _, name := range varyHeaders(res.Header()) {
+		if name == "*" {
+			return false
+		}
+	}
+
+	// without freshness information or validators the entry is useless.
+	h := res.Header()
+	for _, k := range []string{"Cache-Control", "Expires", "ETag", "Last-Modified"} {
+		if h.Get(k) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cacheKey identifies the target of raw along with its credentials, so that
+// responses are not served to requests with other credentials. The
+// credentials are hashed to keep them out of the store.
+func cacheKey(method string, raw *http.Request) string {
+	key := method + " " + raw.URL.String()
+
+	auth, cookie := raw.Header.Values("Authorization"), raw.Header.Values("Cookie")
+	if len(auth) == 0 && len(cookie) == 0 {
+		return key
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(auth, ",") + "\n" + strings.Join(cookie, ",")))
+
+	return key + " " + hex.EncodeToString(sum[:])
+}
+
+func varyHeaders(h http.Header) []string {
+	var names []string
+
+	for _, v := range h.Values("Vary") {
+		for name := range strings.SplitSeq(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// parseCacheControl returns the Cache-Control directives by lowercase name.
+func parseCacheControl(h http.Header) map[string]string {
+	directives := map[string]string{}
+
+	for _, v := range h.Values("Cache-Control") {
+		for d := range strings.SplitSeq(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+			if name != "" {
+				directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+			}
+		}
+	}
+
+	return directives
+}
+
+func parseSeconds(v string) (time.Duration, bool) {
+	s, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil || s < 0 {
+		return 0, false
+	}
+
+	return time.Duration(s) * time.Second, true
+}
@@ -0,0 +1,322 @@
+package rip
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func setupCacheServer(hits *atomic.Int32) *httptest.Server {
+	lastModified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+
+		switch r.URL.Path {
+		case "/max-age":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store, max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"v1"`)
+
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/last-modified":
+			w.Header().Set("Cache-Control", "max-age=0")
+			w.Header().Set("Last-Modified", lastModified)
+
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+		case "/stale-if-error":
+			if hits.Load() > 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Header().Set("Cache-Control", "max-age=1, stale-if-error=60")
+		case "/gzip":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Content-Encoding", EncodingGzip)
+
+			zw := gzip.NewWriter(w)
+			zw.Write([]byte("hit")) //nolint: errcheck
+			zw.Close()              //nolint: errcheck
+
+			return
+		}
+
+		w.Write([]byte("hit " + r.Header.Get("Accept-Language"))) //nolint: errcheck
+	}))
+}
+
+func TestCache(t *testing.T) {
+	type tcase struct {
+		path      string
+		advance   time.Duration
+		header    http.Header
+		expHits   int32
+		expCached bool
+	}
+
+	tests := map[string]tcase{
+		"fresh response is served from cache": {
+			path:      "/max-age",
+			expHits:   1,
+			expCached: true,
+		},
+		"stale response is fetched again": {
+			path:    "/max-age",
+			advance: time.Minute,
+			expHits: 2,
+		},
+		"no-store is not cached": {
+			path:    "/no-store",
+			expHits: 2,
+		},
+		"request no-cache bypasses fresh response": {
+			path:    "/max-age",
+			header:  http.Header{"Cache-Control": []string{"no-cache"}},
+			expHits: 2,
+		},
+		"etag is revalidated": {
+			path:      "/etag",
+			expHits:   2,
+			expCached: true,
+		},
+		"last-modified is revalidated": {
+			path:      "/last-modified",
+			expHits:   2,
+			expCached: true,
+		},
+		"vary mismatch is fetched again": {
+			path:    "/vary",
+			header:  http.Header{"Accept-Language": []string{"de"}},
+			expHits: 2,
+		},
+		"stale response is served on error": {
+			path:      "/stale-if-error",
+			advance:   10 * time.Second,
+			expHits:   2,
+			expCached: true,
+		},
+		"stale-if-error window is exceeded": {
+			path:    "/stale-if-error",
+			advance: 2 * time.Minute,
+			expHits: 2,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var hits atomic.Int32
+
+			server := setupCacheServer(&hits)
+			defer server.Close()
+
+			c, err := NewClient(server.URL, WithCache(NewMemoryStore(10)))
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			now := time.Now()
+			c.cache.now = func() time.Time { return now }
+
+			res, err := c.NR().Execute(t.Context(), http.MethodGet, tc.path)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+
+			if first := res.String(); first != "hit" {
+				t.Errorf("expected body to be readable after caching, got: %q", first)
+			}
+
+			now = now.Add(tc.advance)
+
+			req := c.NR()
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			res, err = req.Execute(t.Context(), http.MethodGet, tc.path)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+			defer res.Close()
+
+			if got := hits.Load(); got != tc.expHits {
+				t.Errorf("expected %d requests, got: %d", tc.expHits, got)
+			}
+
+			if res.FromCache() != tc.expCached {
+				t.Errorf("expected FromCache %v, got: %v", tc.expCached, res.FromCache())
+			}
+
+			if tc.expCached && res.String() != "hit" {
+				t.Errorf("expected cached body, got: %q", res.String())
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	var hits atomic.Int32
+
+	server := setupCacheServer(&hits)
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithCache(NewMemoryStore(10)))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodGet} {
+		res, err := c.NR().Execute(t.Context(), method, "/max-age")
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		res.discard()
+	}
+
+	if got := hits.Load(); got != 3 {
+		t.Errorf("expected PUT to invalidate the cached response, got %d requests", got)
+	}
+}
+
+func TestCacheConcurrentRevalidation(t *testing.T) {
+	var hits atomic.Int32
+
+	server := setupCacheServer(&hits)
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithCache(NewMemoryStore(10)))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	res, err := c.NR().Execute(t.Context(), http.MethodGet, "/etag")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+
+	res.discard()
+
+	var wg sync.WaitGroup
+
+	for range 20 {
+		wg.Go(func() {
+			res, err := c.NR().Execute(t.Context(), http.MethodGet, "/etag")
+			if err != nil {
+				t.Errorf("expected err to be nil, got: %v", err)
+				return
+			}
+			defer res.Close() //nolint: errcheck
+
+			if !res.FromCache() || res.String() != "hit" {
+				t.Errorf("expected cached body, got: %q", res.String())
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+func TestCacheDecodedBody(t *testing.T) {
+	var hits atomic.Int32
+
+	server := setupCacheServer(&hits)
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithCache(NewMemoryStore(10)), WithAcceptEncoding(EncodingGzip))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	for i := range 2 {
+		res, err := c.NR().Execute(t.Context(), http.MethodGet, "/gzip")
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		if res.FromCache() != (i == 1) || res.String() != "hit" {
+			t.Errorf("expected decoded body, got: %q from cache %v", res.String(), res.FromCache())
+		}
+
+		if got := res.ContentEncoding(); i == 1 && got != "" {
+			t.Errorf("expected no Content-Encoding for decoded entry, got: %q", got)
+		}
+
+		res.Close() //nolint: errcheck
+	}
+
+	if hits.Load() != 1 {
+		t.Errorf("expected 1 hit, got: %d", hits.Load())
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	s := NewMemoryStore(2)
+
+	s.Set("a", &CacheEntry{})
+	s.Set("b", &CacheEntry{})
+	s.Get("a")
+	s.Set("c", &CacheEntry{})
+
+	if _, ok := s.Get("b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+
+	if _, ok := s.Get("a"); !ok {
+		t.Error("expected recently used entry to be kept")
+	}
+
+	if s.Len() != 2 {
+		t.Errorf("expected 2 entries, got: %d", s.Len())
+	}
+}
+
+func TestDiskStore(t *testing.T) {
+	s, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+
+	s.Set("GET /test", &CacheEntry{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Etag": []string{`"v1"`}},
+		Body:       []byte("test"),
+	})
+
+	entry, ok := s.Get("GET /test")
+	if !ok {
+		t.Fatal("expected entry to be stored")
+	}
+
+	if entry.StatusCode != http.StatusOK || string(entry.Body) != "test" || entry.Header.Get("ETag") != `"v1"` {
+		t.Errorf("expected entry to round trip, got: %+v", entry)
+	}
+
+	s.Delete("GET /test")
+
+	if _, ok := s.Get("GET /test"); ok {
+		t.Error("expected entry to be deleted")
+	}
+}
@@ -0,0 +1,155 @@
+package rip
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MemoryStore is an in-memory CacheStore evicting the least recently
+// used entries.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryStore creates a MemoryStore holding up to maxEntries entries.
+// maxEntries < 1 means no limit.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+// Get returns the entry for key.
+func (s *MemoryStore) Get(key string) (*CacheEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	s.lru.MoveToFront(el)
+
+	return el.Value.(*memoryItem).entry, true //nolint: forcetypeassert
+}
+
+// Set stores entry for key, evicting the least recently used entry if full.
+func (s *MemoryStore) Set(key string, entry *CacheEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.entries[key]; ok {
+		el.Value.(*memoryItem).entry = entry //nolint: forcetypeassert
+		s.lru.MoveToFront(el)
+
+		return
+	}
+
+	s.entries[key] = s.lru.PushFront(&memoryItem{key: key, entry: entry})
+
+	if s.maxEntries > 0 && s.lru.Len() > s.maxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryItem).key) //nolint: forcetypeassert
+	}
+}
+
+// Delete removes the entry for key.
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.entries[key]; ok {
+		s.lru.Remove(el)
+		delete(s.entries, key)
+	}
+}
+
+// Len returns the number of entries.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lru.Len()
+}
+
+// DiskStore is a CacheStore keeping one JSON file per entry in a directory.
+type DiskStore struct {
+	dir string
+}
+
+// NewDiskStore creates a DiskStore in dir, creating it if necessary.
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &DiskStore{dir: dir}, nil
+}
+
+// Get returns the entry for key.
+func (s *DiskStore) Get(key string) (*CacheEntry, bool) {
+	b, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	entry := &CacheEntry{}
+	if err := json.Unmarshal(b, entry); err != nil {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// Set stores entry for key. The file is replaced atomically.
+func (s *DiskStore) Set(key string, entry *CacheEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	f, err := os.CreateTemp(s.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name()) //nolint: errcheck
+
+	if _, err := f.Write(b); err != nil {
+		f.Close() //nolint: errcheck
+		return
+	}
+
+	if err := f.Close(); err != nil {
+		return
+	}
+
+	_ = os.Rename(f.Name(), s.path(key))
+}
+
+// Delete removes the entry for key.
+func (s *DiskStore) Delete(key string) {
+	_ = os.Remove(s.path(key))
+}
+
+func (s *DiskStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -1,9 +1,11 @@
 package rip
 
 import (
+	"context"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -14,6 +16,25 @@ type Option func(*Client)
 type ClientOptions struct {
 	Header  Header
 	Timeout time.Duration
+	Retry   *RetryPolicy
+
+	RetryAfter    bool
+	MaxRetryAfter time.Duration
+
+	ErrorOnStatus bool
+
+	ArrayFormat    ArrayFormat
+	ValueFormatter ValueFormatter
+	NestingFormat  NestingFormat
+
+	StrictPathParams bool
+	URLMode          URLMode
+
+	MaxBodySize int64
+
+	Compression        string
+	CompressionMinSize int64
+	AcceptEncoding     []string
 }
 
 // Client wraps an http client.
@@ -21,7 +42,19 @@ type Client struct {
 	httpClient *http.Client
 	baseURL    *url.URL
 	options    *ClientOptions
+	limiter    *RateLimiter
+	breaker    *CircuitBreaker
+	cache      *httpCache
+	flights    *flightGroup
+	auth       Authenticator
+	middleware []Middleware
+	codecs     *CodecRegistry
+	encoders   map[string]Encoder
+	decoders   map[string]Decoder
 	Header     Header
+
+	uploadBudget   *TokenBucket
+	downloadBudget *TokenBucket
 }
 
 // WithTimeout sets timeout in seconds on rips httpClient.
@@ -74,8 +107,11 @@ func NewClient(host string, options ...Option) (*Client, error) {
 	transport := defaultTransport()
 
 	client := &Client{
-		baseURL: u,
-		options: &ClientOptions{},
+		baseURL:  u,
+		options:  &ClientOptions{},
+		codecs:   DefaultCodecs(),
+		encoders: defaultEncoders(),
+		decoders: defaultDecoders(),
 		httpClient: &http.Client{
 			Transport: transport,
 		},
@@ -85,6 +121,10 @@ func NewClient(host string, options ...Option) (*Client, error) {
 		option(client)
 	}
 
+	if err := client.checkAcceptEncoding(); err != nil {
+		return &Client{}, err
+	}
+
 	return client, nil
 }
 
@@ -99,24 +139,152 @@ func (c *Client) NR() *Request {
 		}
 	}
 
+	if len(c.options.AcceptEncoding) > 0 {
+		h.Set("Accept-Encoding", strings.Join(c.options.AcceptEncoding, ", "))
+	}
+
 	return &Request{client: c, Header: h}
 }
 
 func (c *Client) execute(req *Request) (*Response, error) {
+	policy := RetryPolicy{MaxAttempts: 1}
+	if c.options.Retry != nil {
+		policy = *c.options.Retry
+	}
+
+	ctx := req.rawRequest.Context()
+	start := time.Now()
+
+	// attempts in flight when the budget is spent are cut off.
+	var deadline time.Time
+	if policy.MaxElapsed > 0 {
+		deadline = start.Add(policy.MaxElapsed)
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := c.do(req, policy.PerAttemptTimeout, deadline)
+
+		wait, retry := c.nextAttempt(ctx, policy, attempt, res, err)
+		if !retry || ctx.Err() != nil || !req.rewindable() {
+			return res, err
+		}
+
+		if policy.MaxElapsed > 0 && time.Since(start)+wait > policy.MaxElapsed {
+			return res, err
+		}
+
+		res.discard()
+
+		if sErr := sleep(ctx, wait); sErr != nil {
+			return res, sErr
+		}
+	}
+}
+
+// nextAttempt returns how long to wait before the next attempt and
+// whether there should be one at all.
+func (c *Client) nextAttempt(
+	ctx context.Context,
+	policy RetryPolicy,
+	attempt int,
+	res *Response,
+	err error,
+) (time.Duration, bool) {
+	if c.options.RetryAfter && err == nil && retryAfterStatus(res.StatusCode()) {
+		if wait, ok := res.RetryAfter(); ok {
+			if attempt >= max(policy.MaxAttempts, 2) {
+				return 0, false
+			}
+
+			if c.options.MaxRetryAfter > 0 && wait > c.options.MaxRetryAfter {
+				return 0, false
+			}
+
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+				return 0, false
+			}
+
+			return wait, true
+		}
+	}
+
+	if attempt >= policy.MaxAttempts || !policy.ShouldRetry(res, err) {
+		return 0, false
+	}
+
+	return policy.backoff(attempt), true
+}
+
+// do performs a single attempt of req, bounded by timeout and deadline
+// unless they are zero.
+func (c *Client) do(req *Request, timeout time.Duration, deadline time.Time) (*Response, error) {
+	if timeout > 0 && (deadline.IsZero() || time.Now().Add(timeout).Before(deadline)) {
+		deadline = time.Now().Add(timeout)
+	}
+
+	ctx, cancel := req.rawRequest.Context(), context.CancelFunc(func() {})
+	if !deadline.IsZero() {
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+	}
+
+	if c.limiter != nil {
+		key := req.template
+		if req.limitKey != "" {
+			key = req.limitKey
+		}
+
+		if err := c.limiter.Wait(ctx, key); err != nil {
+			cancel()
+			return NewResponse(req, nil), err
+		}
+	}
+
+	raw := req.rawRequest.WithContext(ctx)
+	if raw.GetBody != nil {
+		body, err := raw.GetBody()
+		if err != nil {
+			cancel()
+			return NewResponse(req, nil), err
+		}
+		raw.Body = body
+	}
+
+	req.wrapUpload(ctx, raw)
+
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			cancel()
+			if raw.Body != nil {
+				_ = raw.Body.Close()
+			}
+
+			return NewResponse(req, nil), err
+		}
+	}
+
+	start := time.Now()
+
 	// either caller is responsible to close the request
 	// or Response methods do.
 	//nolint: bodyclose
-	resp, err := c.httpClient.Do(req.rawRequest)
+	resp, err := c.httpClient.Do(raw)
 	if err != nil {
-		return NewResponse(req, resp), err
+		cancel()
+		response := NewResponse(req, resp)
+		c.recordOutcome(response, err, time.Since(start))
+
+		return response, err
 	}
 
 	response := &Response{
 		Request: req, rawResponse: resp,
 	}
 
-	response.body = resp.Body
+	response.body = req.wrapDownload(ctx, resp)
+	response.decode()
 	response.Close = func() (err error) {
+		defer cancel()
+
 		if response.body != nil {
 			err := response.body.Close()
 			if err != nil {
@@ -134,5 +302,13 @@ func (c *Client) execute(req *Request) (*Response, error) {
 		return
 	}
 
+	c.recordOutcome(response, nil, time.Since(start))
+
 	return response, nil
 }
+
+func (c *Client) recordOutcome(res *Response, err error, latency time.Duration) {
+	if c.breaker != nil {
+		c.breaker.record(res, err, latency)
+	}
+}
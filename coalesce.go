@@ -0,0 +1,160 @@
+package rip
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// WithCoalescing shares a single upstream request between identical
+// concurrent GET and HEAD requests. Requests are identical if method, URL
+// and the given headers match. Authorization, Cookie and Range are always
+// compared.
+// The shared response body is buffered and replayed to every caller. The
+// shared request is only canceled once all callers canceled their context.
+func WithCoalescing(headers ...string) Option {
+	return func(c *Client) {
+		c.flights = &flightGroup{
+			headers: append([]string{"Authorization", "Cookie", "Range"}, headers...),
+			calls:   map[string]*flight{},
+		}
+	}
+}
+
+type flightGroup struct {
+	mu      sync.Mutex
+	headers []string
+	calls   map[string]*flight
+}
+
+// flight is an in-flight shared request.
+type flight struct {
+	done    chan struct{}
+	waiters int
+	cancel  context.CancelFunc
+
+	status     string
+	statusCode int
+	header     http.Header
+	body       []byte
+	err        error
+}
+
+// handler wraps next with request coalescing.
+func (g *flightGroup) handler(next Handler) Handler {
+	return func(req *Request) (*Response, error) {
+		raw := req.rawRequest
+		if (raw.Method != http.MethodGet && raw.Method != http.MethodHead) || req.streaming() {
+			return next(req)
+		}
+
+		key := g.key(raw)
+
+		g.mu.Lock()
+		f, ok := g.calls[key]
+		if !ok {
+			f = g.start(key, req, next)
+		}
+		f.waiters++
+		g.mu.Unlock()
+
+		ctx := raw.Context()
+
+		select {
+		case <-f.done:
+			return f.response(req)
+		case <-ctx.Done():
+			g.leave(key, f)
+			return NewResponse(req, nil), ctx.Err()
+		}
+	}
+}
+
+// start runs the shared request detached from the context of the caller.
+// It must be called with g.mu held.
+func (g *flightGroup) start(key string, req *Request, next Handler) *flight {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(req.rawRequest.Context()))
+	f := &flight{done: make(chan struct{}), cancel: cancel}
+	g.calls[key] = f
+
+	shared := *req
+	shared.rawRequest = req.rawRequest.WithContext(ctx)
+
+	go func() {
+		defer cancel()
+		defer close(f.done)
+
+		res, err := next(&shared)
+		if res != nil && res.rawResponse != nil {
+			f.status = res.Status()
+			f.statusCode = res.StatusCode()
+			f.header = res.Header()
+
+			b, bErr := res.BodyE()
+			if err == nil {
+				err = bErr
+			}
+
+			f.body = b
+			_ = res.Close()
+		}
+
+		f.err = err
+
+		g.mu.Lock()
+		if g.calls[key] == f {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+	}()
+
+	return f
+}
+
+// leave removes a caller from f, canceling it if it was the last one.
+func (g *flightGroup) leave(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	f.waiters--
+	if f.waiters > 0 {
+		return
+	}
+
+	f.cancel()
+
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
+
+func (g *flightGroup) key(raw *http.Request) string {
+	var b strings.Builder
+
+	b.WriteString(raw.Method + " " + raw.URL.String())
+
+	for _, h := range g.headers {
+		b.WriteString("\n" + h + ": " + strings.Join(raw.Header.Values(h), ","))
+	}
+
+	return b.String()
+}
+
+// response replays the shared response to a caller.
+func (f *flight) response(req *Request) (*Response, error) {
+	if f.statusCode == 0 {
+		return NewResponse(req, nil), f.err
+	}
+
+	return NewResponse(req, &http.Response{
+		Status:        f.status,
+		StatusCode:    f.statusCode,
+		Header:        f.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(f.body)),
+		ContentLength: int64(len(f.body)),
+		Request:       req.rawRequest,
+	}), f.err
+}
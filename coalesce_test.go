@@ -0,0 +1,153 @@
+package rip
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func setupCoalescingServer(hits *atomic.Int32, release chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+
+		w.Write([]byte("shared " + r.Header.Get("Authorization"))) //nolint: errcheck
+	}))
+}
+
+func TestCoalescing(t *testing.T) {
+	type tcase struct {
+		method  string
+		auth    func(i int) string
+		expHits int32
+	}
+
+	tests := map[string]tcase{
+		"identical gets are coalesced": {
+			method:  http.MethodGet,
+			auth:    func(int) string { return "" },
+			expHits: 1,
+		},
+		"different authorization is not coalesced": {
+			method:  http.MethodGet,
+			auth:    func(i int) string { return string(rune('a' + i)) },
+			expHits: 5,
+		},
+		"posts are not coalesced": {
+			method:  http.MethodPost,
+			auth:    func(int) string { return "" },
+			expHits: 5,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var hits atomic.Int32
+
+			release := make(chan struct{})
+
+			server := setupCoalescingServer(&hits, release)
+			defer server.Close()
+
+			c, err := NewClient(server.URL, WithCoalescing())
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			var wg sync.WaitGroup
+
+			bodies := make([]string, 5)
+			for i := range bodies {
+				wg.Go(func() {
+					req := c.NR()
+					if a := tc.auth(i); a != "" {
+						req.SetHeader("Authorization", a)
+					}
+
+					res, err := req.Execute(t.Context(), tc.method, "/test")
+					if err != nil {
+						t.Errorf("expected err to be nil, got: %v", err)
+						return
+					}
+
+					bodies[i] = res.String()
+				})
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if got := hits.Load(); got != tc.expHits {
+				t.Errorf("expected %d requests, got: %d", tc.expHits, got)
+			}
+
+			for i, b := range bodies {
+				if want := strings.TrimSpace("shared " + tc.auth(i)); b != want {
+					t.Errorf("expected every caller to read the body, got: %q", b)
+				}
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestCoalescingCancel(t *testing.T) {
+	var hits atomic.Int32
+
+	release := make(chan struct{})
+
+	server := setupCoalescingServer(&hits, release)
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithCoalescing())
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	first := make(chan error)
+	go func() {
+		_, err := c.NR().Execute(ctx, http.MethodGet, "/test")
+		first <- err
+	}()
+
+	second := make(chan string)
+	go func() {
+		res, err := c.NR().Execute(t.Context(), http.MethodGet, "/test")
+		if err != nil {
+			second <- err.Error()
+			return
+		}
+
+		second <- res.String()
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+
+	close(release)
+
+	if got := <-second; got != "shared" {
+		t.Errorf("expected the shared request to continue, got: %q", got)
+	}
+
+	if got := hits.Load(); got != 1 {
+		t.Errorf("expected 1 request, got: %d", got)
+	}
+}
@@ -0,0 +1,237 @@
+package rip
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupportedContentType occurs when no codec is registered for a content type.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// ErrUnsupportedValue occurs when a codec cannot handle the given value.
+var ErrUnsupportedValue = errors.New("unsupported value")
+
+const (
+	contentTypeXML  = "application/xml"
+	contentTypeForm = "application/x-www-form-urlencoded"
+)
+
+// Codec marshals and unmarshals bodies of the content types it handles.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	// ContentTypes returns the handled media types. The first one is
+	// sent as Content-Type for request bodies.
+	ContentTypes() []string
+}
+
+// CodecRegistry looks up codecs by content type.
+type CodecRegistry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}
+
+// NewCodecRegistry creates a registry with the given codecs.
+func NewCodecRegistry(codecs ...Codec) *CodecRegistry {
+	reg := &CodecRegistry{codecs: map[string]Codec{}}
+
+	for _, c := range codecs {
+		reg.Register(c)
+	}
+
+	return reg
+}
+
+// DefaultCodecs returns a registry with JSON, XML, form-urlencoded and
+// plain text codecs.
+func DefaultCodecs() *CodecRegistry {
+	return NewCodecRegistry(JSONCodec{}, XMLCodec{}, FormCodec{}, TextCodec{})
+}
+
+var defaultCodecs = DefaultCodecs()
+
+// WithCodec registers a codec on the client, replacing codecs previously
+// registered for the same content types.
+func WithCodec(codec Codec) Option {
+	return func(c *Client) {
+		c.codecs.Register(codec)
+	}
+}
+
+// Register adds c for all its content types.
+func (reg *CodecRegistry) Register(c Codec) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	for _, ct := range c.ContentTypes() {
+		reg.codecs[strings.ToLower(ct)] = c
+	}
+}
+
+// Lookup returns the codec for a content type. Structured syntax suffixes
+// like application/problem+json fall back to the codec of application/json.
+func (reg *CodecRegistry) Lookup(contentType string) (Codec, bool) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	if c, ok := reg.codecs[mt]; ok {
+		return c, true
+	}
+
+	if i := strings.LastIndex(mt, "+"); i != -1 {
+		c, ok := reg.codecs["application/"+mt[i+1:]]
+		return c, ok
+	}
+
+	return nil, false
+}
+
+// Unmarshal decodes b into d using the codec registered for ct.
+func (reg *CodecRegistry) Unmarshal(ct string, b []byte, d any) error {
+	c, ok := reg.Lookup(ct)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedContentType, ct)
+	}
+
+	return c.Unmarshal(b, d)
+}
+
+// JSONCodec handles application/json.
+type JSONCodec struct{}
+
+// Marshal encodes v as JSON.
+func (JSONCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal decodes JSON data into v.
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// ContentTypes returns the JSON media types.
+func (JSONCodec) ContentTypes() []string { return []string{contentTypeJSON, "text/json"} }
+
+// XMLCodec handles application/xml.
+type XMLCodec struct{}
+
+// Marshal encodes v as XML.
+func (XMLCodec) Marshal(v any) ([]byte, error) { return xml.Marshal(v) }
+
+// Unmarshal decodes XML data into v.
+func (XMLCodec) Unmarshal(data []byte, v any) error { return xml.Unmarshal(data, v) }
+
+// ContentTypes returns the XML media types.
+func (XMLCodec) ContentTypes() []string { return []string{contentTypeXML, "text/xml"} }
+
+// FormCodec handles application/x-www-form-urlencoded. It marshals
+// url.Values, map[string]string, map[string][]string and map[string]any
+// and unmarshals into pointers to those. Repeated keys unmarshal into
+// []string for map[string]any.
+type FormCodec struct{}
+
+// Marshal encodes v as form.
+func (FormCodec) Marshal(v any) ([]byte, error) {
+	switch val := v.(type) {
+	case url.Values:
+		return []byte(val.Encode()), nil
+	case map[string][]string:
+		return []byte(url.Values(val).Encode()), nil
+	case map[string]string:
+		values := url.Values{}
+		for k, s := range val {
+			values.Set(k, s)
+		}
+
+		return []byte(values.Encode()), nil
+	case map[string]any:
+		return []byte(valueEncoder{}.values(val).Encode()), nil
+	default:
+		return nil, fmt.Errorf("%w: cannot form-encode %T", ErrUnsupportedValue, v)
+	}
+}
+
+// Unmarshal decodes form data into v.
+func (FormCodec) Unmarshal(data []byte, v any) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	switch dst := v.(type) {
+	case *url.Values:
+		*dst = values
+	case *map[string][]string:
+		*dst = values
+	case *map[string]string:
+		*dst = make(map[string]string, len(values))
+		for k := range values {
+			(*dst)[k] = values.Get(k)
+		}
+	case *map[string]any:
+		*dst = make(map[string]any, len(values))
+		for k, vs := range values {
+			if len(vs) == 1 {
+				(*dst)[k] = vs[0]
+				continue
+			}
+
+			(*dst)[k] = vs
+		}
+	default:
+		return fmt.Errorf("%w: cannot form-decode into %T", ErrUnsupportedValue, v)
+	}
+
+	return nil
+}
+
+// ContentTypes returns the form media type.
+func (FormCodec) ContentTypes() []string { return []string{contentTypeForm} }
+
+// TextCodec handles text/plain. It marshals strings, byte slices,
+// fmt.Stringer and encoding.TextMarshaler and unmarshals into *string,
+// *[]byte and encoding.TextUnmarshaler.
+type TextCodec struct{}
+
+// Marshal encodes v as text.
+func (TextCodec) Marshal(v any) ([]byte, error) {
+	switch val := v.(type) {
+	case string:
+		return []byte(val), nil
+	case []byte:
+		return val, nil
+	case encoding.TextMarshaler:
+		return val.MarshalText()
+	case fmt.Stringer:
+		return []byte(val.String()), nil
+	default:
+		return nil, fmt.Errorf("%w: cannot text-encode %T", ErrUnsupportedValue, v)
+	}
+}
+
+// Unmarshal decodes text data into v.
+func (TextCodec) Unmarshal(data []byte, v any) error {
+	switch dst := v.(type) {
+	case *string:
+		*dst = string(data)
+	case *[]byte:
+		*dst = append((*dst)[:0], data...)
+	case encoding.TextUnmarshaler:
+		return dst.UnmarshalText(data)
+	default:
+		return fmt.Errorf("%w: cannot text-decode into %T", ErrUnsupportedValue, v)
+	}
+
+	return nil
+}
+
+// ContentTypes returns the text media type.
+func (TextCodec) ContentTypes() []string { return []string{"text/plain"} }
@@ -0,0 +1,186 @@
+package rip
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v any) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, ErrUnsupportedValue
+	}
+	return []byte("UPPER:" + s), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v any) error {
+	return TextCodec{}.Unmarshal(data, v)
+}
+
+func (upperCodec) ContentTypes() []string { return []string{"application/x-upper"} }
+
+func TestCodecRegistryLookup(t *testing.T) {
+	type tcase struct {
+		contentType string
+		expCodec    Codec
+	}
+
+	tests := map[string]tcase{
+		"json": {
+			contentType: "application/json; charset=utf-8",
+			expCodec:    JSONCodec{},
+		},
+		"json suffix": {
+			contentType: "application/problem+json",
+			expCodec:    JSONCodec{},
+		},
+		"xml": {
+			contentType: "text/xml",
+			expCodec:    XMLCodec{},
+		},
+		"form": {
+			contentType: "application/x-www-form-urlencoded",
+			expCodec:    FormCodec{},
+		},
+		"text": {
+			contentType: contentTypeTEXT,
+			expCodec:    TextCodec{},
+		},
+		"unknown": {
+			contentType: "application/octet-stream",
+		},
+		"invalid": {
+			contentType: "",
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+
+			got, ok := DefaultCodecs().Lookup(tc.contentType)
+			if ok != (tc.expCodec != nil) {
+				t.Fatalf("expected codec %T, got: %T", tc.expCodec, got)
+			}
+
+			if got != tc.expCodec {
+				t.Errorf("expected codec %T, got: %T", tc.expCodec, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	type item struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	t.Run("xml", func(t *testing.T) {
+		b, err := XMLCodec{}.Marshal(item{Name: "test"})
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		got := item{}
+		if err := Unmarshal(contentTypeXML, b, &got); err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		if got.Name != "test" {
+			t.Errorf("expected name test, got: %s", got.Name)
+		}
+	})
+
+	t.Run("form", func(t *testing.T) {
+		b, err := FormCodec{}.Marshal(map[string]string{"a": "1", "b": "x y"})
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		if string(b) != "a=1&b=x+y" {
+			t.Errorf("expected a=1&b=x+y, got: %s", b)
+		}
+
+		got := url.Values{}
+		if err := Unmarshal(contentTypeForm, b, &got); err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		if got.Get("b") != "x y" {
+			t.Errorf("expected b to be x y, got: %s", got.Get("b"))
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		got := ""
+		if err := Unmarshal(contentTypeTEXT, []byte("hello"), &got); err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		if got != "hello" {
+			t.Errorf("expected hello, got: %s", got)
+		}
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		err := Unmarshal("application/octet-stream", []byte{}, &struct{}{})
+		if !errors.Is(err, ErrUnsupportedContentType) {
+			t.Errorf("expected ErrUnsupportedContentType, got: %v", err)
+		}
+	})
+}
+
+func TestWithCodec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithCodec(upperCodec{}))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	got := ""
+	res, err := c.NR().
+		SetHeader("Content-Type", "application/x-upper").
+		SetBody("test").
+		SetResult(&got).
+		Execute(t.Context(), http.MethodPost, "/")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if got != "UPPER:test" {
+		t.Errorf("expected body to be encoded by custom codec, got: %s", got)
+	}
+}
+
+func TestSetBodyMarshalError(t *testing.T) {
+	c, err := NewClient("http://localhost")
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	_, err = c.NR().
+		SetHeader("Content-Type", contentTypeForm).
+		SetBody(42).
+		Execute(t.Context(), http.MethodPost, "/")
+	if !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("expected ErrUnsupportedValue, got: %v", err)
+	}
+}
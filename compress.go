@@ -0,0 +1,194 @@
+package rip
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings, see RFC 9110 8.4.1.
+const (
+	EncodingGzip     = "gzip"
+	EncodingDeflate  = "deflate"
+	EncodingZstd     = "zstd"
+	EncodingBrotli   = "br"
+	EncodingIdentity = "identity"
+)
+
+// ErrUnsupportedEncoding occurs when no encoder or decoder is registered
+// for a content coding.
+var ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// Encoder returns a writer compressing into w.
+type Encoder func(w io.Writer) (io.WriteCloser, error)
+
+// WithEncoder registers an encoder for a content coding. gzip, deflate,
+// zstd and br are registered by default.
+func WithEncoder(name string, enc Encoder) Option {
+	return func(c *Client) {
+		c.encoders[strings.ToLower(name)] = enc
+	}
+}
+
+// WithRequestCompression compresses request bodies of at least minSize
+// bytes with the content coding algo and sets Content-Encoding. Bodies of
+// unknown size are always compressed. Buffered bodies are compressed
+// upfront, readers are compressed while streaming and sent without
+// Content-Length.
+func WithRequestCompression(algo string, minSize int64) Option {
+	return func(c *Client) {
+		c.options.Compression = algo
+		c.options.CompressionMinSize = minSize
+	}
+}
+
+// SetCompression overrides the request compression of the client for this
+// request. EncodingIdentity disables it.
+func (r *Request) SetCompression(algo string, minSize int64) *Request {
+	r.compression = &compression{algo: algo, minSize: minSize}
+
+	return r
+}
+
+type compression struct {
+	algo    string
+	minSize int64
+}
+
+func defaultEncoders() map[string]Encoder {
+	return map[string]Encoder{
+		EncodingGzip: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		// deflate is the zlib format, see RFC 9110 8.4.1.2.
+		EncodingDeflate: func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+		EncodingZstd: func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w)
+		},
+		EncodingBrotli: func(w io.Writer) (io.WriteCloser, error) {
+			return brotli.NewWriter(w), nil
+		},
+	}
+}
+
+// compress encodes the body of the raw request. body is the reader
+// passed to http.NewRequest.
+func (r *Request) compress(body io.Reader) error {
+	algo, minSize := r.client.options.Compression, r.client.options.CompressionMinSize
+	if r.compression != nil {
+		algo, minSize = r.compression.algo, r.compression.minSize
+	}
+
+	raw := r.rawRequest
+	algo = strings.ToLower(algo)
+
+	if algo == "" || algo == EncodingIdentity || raw.Body == nil || raw.Body == http.NoBody {
+		return nil
+	}
+
+	// the body is encoded already.
+	if raw.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+
+	if raw.ContentLength > 0 && raw.ContentLength < minSize {
+		return nil
+	}
+
+	enc, ok := r.client.encoders[algo]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedEncoding, algo)
+	}
+
+	raw.Header.Set("Content-Encoding", algo)
+
+	switch body.(type) {
+	case *bytes.Buffer, *bytes.Reader, *strings.Reader:
+		return r.compressBuffered(enc)
+	}
+
+	// sources are opened on the first Read, so that encoding never
+	// starts for a body replaced by GetBody before it is sent.
+	var mu sync.Mutex
+
+	src := raw.Body
+	raw.Body = encodeStream(enc, &mu, func() (io.ReadCloser, error) { return src, nil })
+	raw.ContentLength = -1
+
+	if getBody := raw.GetBody; getBody != nil {
+		raw.GetBody = func() (io.ReadCloser, error) {
+			return encodeStream(enc, &mu, getBody), nil
+		}
+	}
+
+	return nil
+}
+
+// compressBuffered compresses an in-memory body upfront, so that its
+// length is known and it can be sent again.
+func (r *Request) compressBuffered(enc Encoder) error {
+	raw := r.rawRequest
+
+	var buf bytes.Buffer
+
+	w, err := enc(&buf)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, raw.Body); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	b := buf.Bytes()
+
+	raw.Body = io.NopCloser(bytes.NewReader(b))
+	raw.ContentLength = int64(len(b))
+	raw.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+
+	return nil
+}
+
+// encodeStream compresses the source opened by open while it is read.
+// Nothing is opened before the first Read.
+func encodeStream(enc Encoder, mu *sync.Mutex, open func() (io.ReadCloser, error)) io.ReadCloser {
+	return pipeReader(mu, func(w io.Writer) error {
+		return encode(enc, w, open)
+	})
+}
+
+func encode(enc Encoder, dst io.Writer, open func() (io.ReadCloser, error)) error {
+	src, err := open()
+	if err != nil {
+		return err
+	}
+	defer src.Close() //nolint: errcheck
+
+	w, err := enc(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, src); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
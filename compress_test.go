@@ -0,0 +1,276 @@
+package rip
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func setupCompressionServer(attempts *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/flaky" && attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var (
+			body io.Reader = r.Body
+			err  error
+		)
+
+		switch r.Header.Get("Content-Encoding") {
+		case EncodingGzip, "x-gzip":
+			body, err = gzip.NewReader(r.Body)
+		case EncodingDeflate:
+			body, err = zlib.NewReader(r.Body)
+		case EncodingZstd:
+			body, err = zstd.NewReader(r.Body)
+		case EncodingBrotli:
+			body = brotli.NewReader(r.Body)
+		}
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		b, err := io.ReadAll(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("X-Content-Encoding", r.Header.Get("Content-Encoding"))
+		w.Header().Set("X-Content-Length", strconv.FormatInt(r.ContentLength, 10))
+		w.Write(b) //nolint: errcheck
+	}))
+}
+
+func TestRequestCompression(t *testing.T) {
+	type tcase struct {
+		options     []Option
+		request     func(r *Request) *Request
+		path        string
+		expEncoding string
+		expChunked  bool
+		expErr      error
+	}
+
+	body := strings.Repeat("rip ", 1000)
+
+	tests := map[string]tcase{
+		"gzip buffered body": {
+			options:     []Option{WithRequestCompression(EncodingGzip, 100)},
+			request:     func(r *Request) *Request { return r.SetBody(strings.NewReader(body)) },
+			expEncoding: EncodingGzip,
+		},
+		"deflate buffered body": {
+			options:     []Option{WithRequestCompression(EncodingDeflate, 0)},
+			request:     func(r *Request) *Request { return r.SetBody(bytes.NewBufferString(body)) },
+			expEncoding: EncodingDeflate,
+		},
+		"zstd buffered body": {
+			options:     []Option{WithRequestCompression(EncodingZstd, 0)},
+			request:     func(r *Request) *Request { return r.SetBody(strings.NewReader(body)) },
+			expEncoding: EncodingZstd,
+		},
+		"brotli streamed reader": {
+			options:     []Option{WithRequestCompression(EncodingBrotli, 0)},
+			request:     func(r *Request) *Request { return r.SetBody(io.NopCloser(strings.NewReader(body))) },
+			expEncoding: EncodingBrotli,
+			expChunked:  true,
+		},
+		"body below min size": {
+			options: []Option{WithRequestCompression(EncodingGzip, 1<<20)},
+			request: func(r *Request) *Request { return r.SetBody(strings.NewReader(body)) },
+		},
+		"streamed reader": {
+			options:     []Option{WithRequestCompression(EncodingGzip, 100)},
+			request:     func(r *Request) *Request { return r.SetBody(io.NopCloser(strings.NewReader(body))) },
+			expEncoding: EncodingGzip,
+			expChunked:  true,
+		},
+		"content length of stream is unset": {
+			options: []Option{WithRequestCompression(EncodingGzip, 100)},
+			request: func(r *Request) *Request {
+				return r.SetBody(io.NopCloser(strings.NewReader(body))).SetContentLength(int64(len(body)))
+			},
+			expEncoding: EncodingGzip,
+			expChunked:  true,
+		},
+		"request override": {
+			request: func(r *Request) *Request {
+				return r.SetBody(strings.NewReader(body)).SetCompression(EncodingGzip, 0)
+			},
+			expEncoding: EncodingGzip,
+		},
+		"request disables compression": {
+			options: []Option{WithRequestCompression(EncodingGzip, 0)},
+			request: func(r *Request) *Request {
+				return r.SetBody(strings.NewReader(body)).SetCompression(EncodingIdentity, 0)
+			},
+		},
+		"unregistered encoding": {
+			options: []Option{WithRequestCompression("compress", 0)},
+			request: func(r *Request) *Request { return r.SetBody(strings.NewReader(body)) },
+			expErr:  ErrUnsupportedEncoding,
+		},
+		"registered encoding": {
+			options: []Option{
+				WithRequestCompression("x-gzip", 0),
+				WithEncoder("x-gzip", func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil }),
+			},
+			request:     func(r *Request) *Request { return r.SetBody(strings.NewReader(body)) },
+			expEncoding: "x-gzip",
+		},
+		"retried body is compressed again": {
+			options: []Option{
+				WithRequestCompression(EncodingGzip, 0),
+				WithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+			},
+			request:     func(r *Request) *Request { return r.SetBody(io.NopCloser(strings.NewReader(body))) },
+			path:        "/flaky",
+			expEncoding: EncodingGzip,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var attempts atomic.Int32
+
+			server := setupCompressionServer(&attempts)
+			defer server.Close()
+
+			c, err := NewClient(server.URL, tc.options...)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			req := tc.request(c.NR())
+
+			res, err := req.Execute(t.Context(), http.MethodPost, "/"+strings.TrimPrefix(tc.path, "/"))
+			if !errors.Is(err, tc.expErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expErr, err)
+			}
+
+			if tc.expErr != nil {
+				return
+			}
+			defer res.Close()
+
+			if res.String() != strings.TrimSpace(body) {
+				t.Errorf("expected body to round trip, got %d bytes, status %d", len(res.String()), res.StatusCode())
+			}
+
+			if got := res.Header().Get("X-Content-Encoding"); got != tc.expEncoding {
+				t.Errorf("expected Content-Encoding %q, got: %q", tc.expEncoding, got)
+			}
+
+			length, _ := strconv.Atoi(res.Header().Get("X-Content-Length"))
+			if tc.expChunked != (length == -1) {
+				t.Errorf("expected chunked %v, got Content-Length: %d", tc.expChunked, length)
+			}
+
+			if tc.expEncoding != "" && !tc.expChunked && length >= len(body) {
+				t.Errorf("expected compressed Content-Length, got: %d", length)
+			}
+
+			if req.Header.Get("Content-Encoding") != "" {
+				t.Error("expected Content-Encoding not to leak into the request header")
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestRequestCompressionRewindable(t *testing.T) {
+	type tcase struct {
+		request func(t *testing.T, r *Request) *Request
+		expBody []byte
+	}
+
+	data := make([]byte, 4<<20)
+	_, _ = rand.Read(data)
+
+	tests := map[string]tcase{
+		"file body": {
+			request: func(t *testing.T, r *Request) *Request {
+				t.Helper()
+
+				path := filepath.Join(t.TempDir(), "data")
+				if err := os.WriteFile(path, data, 0o600); err != nil {
+					t.Fatal(err)
+				}
+
+				f, err := os.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { f.Close() }) //nolint: errcheck
+
+				return r.SetBody(f)
+			},
+			expBody: data,
+		},
+		"multipart body": {
+			request: func(t *testing.T, r *Request) *Request {
+				t.Helper()
+
+				return r.AddFormField("title", "holiday").AddFile("photo", "photo.jpg", bytes.NewReader(data[:1<<10]))
+			},
+			expBody: data[:1<<10],
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var attempts atomic.Int32
+
+			server := setupCompressionServer(&attempts)
+			defer server.Close()
+
+			c, err := NewClient(server.URL, WithRequestCompression(EncodingGzip, 0))
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			ctx, cancel := context.WithTimeout(t.Context(), 2*time.Second)
+			defer cancel()
+
+			res, err := tc.request(t, c.NR()).Execute(ctx, http.MethodPost, "/")
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+			defer res.Close()
+
+			if got := res.Body(); !bytes.Contains(got, tc.expBody) {
+				t.Errorf("expected body to round trip, got %d bytes", len(got))
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -0,0 +1,186 @@
+package rip
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Decoder returns a reader decompressing r.
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+// WithDecoder registers a decoder for a content coding. gzip, deflate,
+// zstd and br are registered by default.
+func WithDecoder(name string, dec Decoder) Option {
+	return func(c *Client) {
+		c.decoders[strings.ToLower(name)] = dec
+	}
+}
+
+// WithAcceptEncoding advertises the content codings as Accept-Encoding and
+// decodes response bodies encoded with them in the body accessors. This
+// replaces the transparent gzip decompression of net/http, so that
+// Content-Encoding and the compressed size stay visible. NewClient fails
+// with ErrUnsupportedEncoding for codings without a registered decoder.
+func WithAcceptEncoding(encodings ...string) Option {
+	return func(c *Client) {
+		c.options.AcceptEncoding = encodings
+	}
+}
+
+func defaultDecoders() map[string]Decoder {
+	return map[string]Decoder{
+		EncodingGzip: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		EncodingDeflate: func(r io.Reader) (io.ReadCloser, error) {
+			// deflate should be the zlib format, but some servers send
+			// raw deflate, see RFC 9110 8.4.1.2.
+			br := bufio.NewReader(r)
+
+			h, err := br.Peek(2)
+			if err == nil && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0 {
+				return zlib.NewReader(br)
+			}
+
+			return flate.NewReader(br), nil
+		},
+		EncodingZstd: func(r io.Reader) (io.ReadCloser, error) {
+			d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return nil, err
+			}
+
+			return d.IOReadCloser(), nil
+		},
+		EncodingBrotli: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(brotli.NewReader(r)), nil
+		},
+	}
+}
+
+// checkAcceptEncoding ensures a decoder is registered for every coding
+// advertised with WithAcceptEncoding.
+func (c *Client) checkAcceptEncoding() error {
+	for _, e := range c.options.AcceptEncoding {
+		name, _, _ := strings.Cut(e, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if _, ok := c.decoders[name]; !ok && name != EncodingIdentity {
+			return fmt.Errorf("%w: %q", ErrUnsupportedEncoding, e)
+		}
+	}
+
+	return nil
+}
+
+// ContentEncoding returns the Content-Encoding of the response.
+func (r *Response) ContentEncoding() string {
+	return r.Header().Get("Content-Encoding")
+}
+
+// CompressedSize returns the bytes of an encoded body read from the
+// connection so far, the full compressed size once the body was read.
+// It reports false if the body is not decoded by the client.
+func (r *Response) CompressedSize() (int64, bool) {
+	if r.encoded == nil {
+		return 0, false
+	}
+
+	return r.encoded.n, true
+}
+
+// decode wraps the body with the decoders of its Content-Encoding if the
+// client was configured with WithAcceptEncoding. Bodies with codings the
+// client has no decoder for are left as is.
+func (r *Response) decode() {
+	if r.body == nil || r.Request == nil || r.Request.client == nil ||
+		len(r.Request.client.options.AcceptEncoding) == 0 {
+		return
+	}
+
+	var decoders []Decoder
+
+	for _, e := range slices.Backward(strings.Split(r.ContentEncoding(), ",")) {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" || e == EncodingIdentity {
+			continue
+		}
+
+		dec, ok := r.Request.client.decoders[e]
+		if !ok {
+			return
+		}
+
+		decoders = append(decoders, dec)
+	}
+
+	if len(decoders) == 0 {
+		return
+	}
+
+	r.encoded = &countingReader{ReadCloser: r.body}
+	r.body = &decodingReader{src: r.encoded, decoders: decoders}
+}
+
+// countingReader counts the bytes read.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// decodingReader applies decoders on first read, as creating
+// them may already read from src.
+type decodingReader struct {
+	src      io.ReadCloser
+	decoders []Decoder
+	rd       io.Reader
+	closers  []io.Closer
+	err      error
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	if d.rd == nil && d.err == nil {
+		d.rd = d.src
+
+		for _, dec := range d.decoders {
+			rc, err := dec(d.rd)
+			if err != nil {
+				d.err = err
+				break
+			}
+
+			d.rd = rc
+			d.closers = append(d.closers, rc)
+		}
+	}
+
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	return d.rd.Read(p)
+}
+
+func (d *decodingReader) Close() error {
+	for _, c := range slices.Backward(d.closers) {
+		_ = c.Close()
+	}
+
+	return d.src.Close()
+}
@@ -0,0 +1,198 @@
+package rip
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func setupDecompressionServer(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := strings.TrimPrefix(r.URL.Path, "/")
+
+		var (
+			buf bytes.Buffer
+			enc io.WriteCloser
+		)
+
+		switch encoding {
+		case EncodingGzip, "x-gzip", "compress":
+			enc = gzip.NewWriter(&buf)
+		case EncodingZstd:
+			enc, _ = zstd.NewWriter(&buf)
+		case EncodingBrotli:
+			enc = brotli.NewWriter(&buf)
+		case EncodingDeflate:
+			enc = zlib.NewWriter(&buf)
+		case "raw-deflate":
+			enc, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+			encoding = EncodingDeflate
+		default:
+			w.Write([]byte(r.Header.Get("Accept-Encoding"))) //nolint: errcheck
+			return
+		}
+
+		enc.Write([]byte(body)) //nolint: errcheck
+		enc.Close()             //nolint: errcheck
+
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(buf.Bytes()) //nolint: errcheck
+	}))
+}
+
+func TestResponseDecompression(t *testing.T) {
+	type tcase struct {
+		options     []Option
+		header      Header
+		path        string
+		expEncoding string
+		expDecoded  bool
+		expCounted  bool
+	}
+
+	body := strings.Repeat("rip ", 1000)
+
+	tests := map[string]tcase{
+		"gzip": {
+			options:     []Option{WithAcceptEncoding(EncodingGzip)},
+			path:        "/gzip",
+			expEncoding: EncodingGzip,
+			expDecoded:  true,
+			expCounted:  true,
+		},
+		"deflate": {
+			options:     []Option{WithAcceptEncoding(EncodingDeflate)},
+			path:        "/deflate",
+			expEncoding: EncodingDeflate,
+			expDecoded:  true,
+			expCounted:  true,
+		},
+		"raw deflate": {
+			options:     []Option{WithAcceptEncoding(EncodingDeflate)},
+			path:        "/raw-deflate",
+			expEncoding: EncodingDeflate,
+			expDecoded:  true,
+			expCounted:  true,
+		},
+		"zstd": {
+			options:     []Option{WithAcceptEncoding(EncodingZstd)},
+			path:        "/zstd",
+			expEncoding: EncodingZstd,
+			expDecoded:  true,
+			expCounted:  true,
+		},
+		"brotli": {
+			options:     []Option{WithAcceptEncoding(EncodingBrotli)},
+			path:        "/br",
+			expEncoding: EncodingBrotli,
+			expDecoded:  true,
+			expCounted:  true,
+		},
+		"registered decoder": {
+			options: []Option{
+				WithAcceptEncoding("x-gzip"),
+				WithDecoder("x-gzip", func(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }),
+			},
+			path:        "/x-gzip",
+			expEncoding: "x-gzip",
+			expDecoded:  true,
+			expCounted:  true,
+		},
+		"unregistered decoder is left as is": {
+			options:     []Option{WithAcceptEncoding(EncodingGzip)},
+			path:        "/compress",
+			expEncoding: "compress",
+		},
+		"without option the body is left as received": {
+			header:      Header{"Accept-Encoding": EncodingGzip},
+			path:        "/gzip",
+			expEncoding: EncodingGzip,
+		},
+		"transparent gzip of net/http": {
+			path:       "/gzip",
+			expDecoded: true,
+		},
+	}
+
+	server := setupDecompressionServer(body)
+	defer server.Close()
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			c, err := NewClient(server.URL, tc.options...)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			res, err := c.NR().SetHeaders(tc.header).Execute(t.Context(), http.MethodGet, tc.path)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+			defer res.Close()
+
+			if tc.header != nil && !tc.expDecoded {
+				zr, err := gzip.NewReader(bytes.NewReader(res.Body()))
+				if err != nil {
+					t.Fatalf("expected raw gzip body, got: %v", err)
+				}
+
+				if raw, _ := io.ReadAll(zr); string(raw) != body {
+					t.Errorf("expected raw body to decode to the sent body")
+				}
+			}
+
+			if decoded := res.String() == strings.TrimSpace(body); decoded != tc.expDecoded {
+				t.Errorf("expected decoded %v, got body of %d bytes", tc.expDecoded, len(res.String()))
+			}
+
+			if got := res.ContentEncoding(); got != tc.expEncoding {
+				t.Errorf("expected Content-Encoding %q, got: %q", tc.expEncoding, got)
+			}
+
+			size, ok := res.CompressedSize()
+			if ok != tc.expCounted || (ok && (size == 0 || size >= int64(len(body)))) {
+				t.Errorf("expected compressed size, got: %d, %v", size, ok)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestAcceptEncoding(t *testing.T) {
+	server := setupDecompressionServer("")
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithAcceptEncoding(EncodingZstd, EncodingBrotli, EncodingGzip))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	res, err := c.NR().Execute(t.Context(), http.MethodGet, "/")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if got := res.String(); got != "zstd, br, gzip" {
+		t.Errorf("expected Accept-Encoding to be advertised, got: %q", got)
+	}
+
+	_, err = NewClient(server.URL, WithAcceptEncoding(EncodingGzip, "compress"))
+	if !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Errorf("expected coding without decoder to be rejected, got: %v", err)
+	}
+}
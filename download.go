@@ -0,0 +1,343 @@
+package rip
+
+import (
+	"context"
+	"crypto/md5" //nolint: gosec // Content-MD5 is defined as MD5
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	partSuffix          = ".part"
+	validatorSuffix     = ".part.validator"
+	defaultDownloadMode = 0o644
+)
+
+// ErrChecksumMismatch occurs when a downloaded file does not match its checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ProgressFunc reports the number of bytes transferred so far and the
+// total size, -1 if unknown.
+type ProgressFunc func(transferred, total int64)
+
+// DownloadOptions configures Request.Download.
+type DownloadOptions struct {
+	// Progress is called whenever data was written to the file.
+	Progress ProgressFunc
+	// SHA256 is the expected hex encoded SHA-256 checksum of the file.
+	SHA256 string
+	// ContentMD5 verifies the Content-MD5 header, if the server sends it
+	// for the complete file.
+	ContentMD5 bool
+	// Resumes is how often an interrupted transfer is resumed.
+	Resumes int
+	// Mode of the file, defaults to 0644.
+	Mode os.FileMode
+}
+
+// Download streams the body of a GET request for path to the file dst.
+// The data is written to dst.part first, which is renamed to dst once the
+// transfer is complete and verified. An existing dst.part of an earlier
+// attempt is resumed with a Range request if the server sent an ETag or
+// Last-Modified validator for it, interrupted transfers are resumed up to
+// opts.Resumes times. The body bypasses the cache and request coalescing.
+// The returned response has been read and closed.
+func (r *Request) Download(ctx context.Context, path, dst string, opts DownloadOptions) (*Response, error) {
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	defer func(stream bool) { r.stream = stream }(r.stream)
+
+	r.stream = true
+
+	// offsets of decompressed bodies do not match ranges.
+	if accept, ok := r.Header["Accept-Encoding"]; ok {
+		defer func() { r.Header["Accept-Encoding"] = accept }()
+	} else {
+		defer r.Header.Del("Accept-Encoding")
+	}
+
+	r.Header.Set("Accept-Encoding", EncodingIdentity)
+
+	defer func() {
+		r.Header.Del("Range")
+		r.Header.Del("If-Range")
+	}()
+
+	d := &download{req: r, part: dst + partSuffix, opts: opts, total: -1}
+
+	for attempt := 0; ; attempt++ {
+		res, err := d.fetch(ctx, path)
+		if err == nil {
+			err = d.verify(res)
+		}
+
+		switch {
+		case err == nil:
+			return res, d.finish(dst)
+		case errors.Is(err, errInterrupted) && attempt < opts.Resumes && ctx.Err() == nil:
+			continue
+		case errors.Is(err, ErrChecksumMismatch):
+			d.remove()
+		}
+
+		return res, err
+	}
+}
+
+// errInterrupted wraps errors of reading the body.
+var errInterrupted = errors.New("download interrupted")
+
+type download struct {
+	req   *Request
+	part  string
+	opts  DownloadOptions
+	total int64
+	// full reports whether the last response carried the complete file.
+	full bool
+}
+
+// fetch requests the missing part of the file and appends it to the part file.
+func (d *download) fetch(ctx context.Context, path string) (*Response, error) {
+	offset, validator := d.resumable()
+
+	if offset > 0 {
+		d.req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		d.req.Header.Set("If-Range", validator)
+	} else {
+		d.req.Header.Del("Range")
+		d.req.Header.Del("If-Range")
+	}
+
+	res, err := d.req.Execute(ctx, http.MethodGet, path)
+
+	var httpErr *HTTPError
+	if err != nil && (!errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusRequestedRangeNotSatisfiable) {
+		return res, err
+	}
+	defer res.Close() //nolint: errcheck
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+
+	switch res.StatusCode() {
+	case http.StatusOK:
+		d.full = true
+		d.total = res.ContentLength()
+		flag |= os.O_TRUNC
+
+		if err := d.saveValidator(res.Header()); err != nil {
+			return res, err
+		}
+	case http.StatusPartialContent:
+		start, total, ok := parseContentRange(res.Header().Get("Content-Range"))
+		if !ok || start != offset {
+			d.remove()
+			return res, fmt.Errorf("%w: unexpected Content-Range %q", errInterrupted, res.Header().Get("Content-Range"))
+		}
+
+		d.full = false
+		d.total = total
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the part file is complete if the range starts at its end.
+		if _, total, ok := parseContentRange(res.Header().Get("Content-Range")); ok && total == offset {
+			d.total = total
+			return res, nil
+		}
+
+		d.remove()
+
+		return res, fmt.Errorf("%w: range not satisfiable", errInterrupted)
+	default:
+		return res, newHTTPError(res)
+	}
+
+	f, err := os.OpenFile(d.part, flag, d.mode())
+	if err != nil {
+		return res, err
+	}
+
+	if err := d.write(f, res); err != nil {
+		f.Close() //nolint: errcheck
+		return res, err
+	}
+
+	return res, f.Close()
+}
+
+// write copies the body of res to f.
+func (d *download) write(f *os.File, res *Response) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	written := info.Size()
+
+	// a body buffered by middleware may be truncated.
+	if err := res.Err(); err != nil {
+		return err
+	}
+
+	body := res.RawBody()
+	if body == nil {
+		return nil
+	}
+
+	if d.opts.Progress != nil {
+		d.opts.Progress(written, d.total)
+		body = &transferReader{ReadCloser: body, n: written, total: d.total, progress: d.opts.Progress}
+	}
+
+	if _, err := io.Copy(f, body); err != nil {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			return err
+		}
+
+		return fmt.Errorf("%w: %w", errInterrupted, err)
+	}
+
+	return f.Sync()
+}
+
+// verify checks the part file against the expected checksums.
+func (d *download) verify(res *Response) error {
+	contentMD5 := ""
+	if d.opts.ContentMD5 && d.full {
+		contentMD5 = res.Header().Get("Content-MD5")
+	}
+
+	if d.opts.SHA256 == "" && contentMD5 == "" {
+		return nil
+	}
+
+	f, err := os.Open(d.part)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint: errcheck
+
+	sha, sum := sha256.New(), md5.New() //nolint: gosec
+
+	if _, err := io.Copy(io.MultiWriter(sha, sum), f); err != nil {
+		return err
+	}
+
+	if d.opts.SHA256 != "" && !strings.EqualFold(hex.EncodeToString(sha.Sum(nil)), d.opts.SHA256) {
+		return fmt.Errorf("%w: sha256 of %s", ErrChecksumMismatch, d.part)
+	}
+
+	if contentMD5 != "" && contentMD5 != base64.StdEncoding.EncodeToString(sum.Sum(nil)) {
+		return fmt.Errorf("%w: Content-MD5 of %s", ErrChecksumMismatch, d.part)
+	}
+
+	return nil
+}
+
+// finish moves the part file into place.
+func (d *download) finish(dst string) error {
+	if err := os.Rename(d.part, dst); err != nil {
+		return err
+	}
+
+	_ = os.Remove(d.validatorPath())
+
+	return nil
+}
+
+// resumable returns the size and validator of a resumable part file.
+func (d *download) resumable() (int64, string) {
+	validator, err := os.ReadFile(d.validatorPath())
+	if err != nil || len(validator) == 0 {
+		return 0, ""
+	}
+
+	info, err := os.Stat(d.part)
+	if err != nil {
+		return 0, ""
+	}
+
+	return info.Size(), string(validator)
+}
+
+// saveValidator stores the strong ETag or Last-Modified of the file to
+// resume with If-Range, see RFC 9110 13.1.5.
+func (d *download) saveValidator(h http.Header) error {
+	validator := h.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = h.Get("Last-Modified")
+	}
+
+	if validator == "" {
+		_ = os.Remove(d.validatorPath())
+		return nil
+	}
+
+	return os.WriteFile(d.validatorPath(), []byte(validator), d.mode())
+}
+
+func (d *download) remove() {
+	_ = os.Remove(d.part)
+	_ = os.Remove(d.validatorPath())
+}
+
+func (d *download) validatorPath() string {
+	return strings.TrimSuffix(d.part, partSuffix) + validatorSuffix
+}
+
+func (d *download) mode() os.FileMode {
+	if d.opts.Mode == 0 {
+		return defaultDownloadMode
+	}
+
+	return d.opts.Mode
+}
+
+// parseContentRange parses "bytes start-end/total" and "bytes */total".
+// total is -1 if unknown.
+func parseContentRange(v string) (int64, int64, bool) {
+	rng, ok := strings.CutPrefix(v, "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+
+	rng, size, ok := strings.Cut(rng, "/")
+	if !ok {
+		return 0, 0, false
+	}
+
+	total := int64(-1)
+	if size != "*" {
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+
+		total = n
+	}
+
+	if rng == "*" {
+		return 0, total, true
+	}
+
+	first, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return start, total, true
+}
@@ -0,0 +1,193 @@
+package rip
+
+import (
+	"bytes"
+	"crypto/md5" //nolint: gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var downloadData = bytes.Repeat([]byte("OSM DATA "), 1000)
+
+func setupDownloadServer(requests *atomic.Int32, ranges *[]string) *httptest.Server {
+	sum := md5.Sum(downloadData) //nolint: gosec
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		*ranges = append(*ranges, r.Header.Get("Range"))
+
+		w.Header().Set("ETag", `"v1"`)
+
+		switch r.URL.Path {
+		case "/interrupted":
+			if n == 1 {
+				// announce the full length, but cut the transfer short.
+				w.Header().Set("Content-Length", strconv.Itoa(len(downloadData)))
+				w.WriteHeader(http.StatusOK)
+				w.Write(downloadData[:len(downloadData)/2]) //nolint: errcheck
+
+				return
+			}
+		case "/md5":
+			w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		case "/bad-md5":
+			w.Header().Set("Content-MD5", "bad")
+		}
+
+		http.ServeContent(w, r, "data.osm.pbf", time.Time{}, bytes.NewReader(downloadData))
+	}))
+}
+
+func TestDownload(t *testing.T) {
+	type tcase struct {
+		path      string
+		opts      DownloadOptions
+		options   []Option
+		partial   bool
+		expErr    error
+		expRanges []string
+	}
+
+	sum := sha256.Sum256(downloadData)
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := map[string]tcase{
+		"complete download": {
+			path:      "/file",
+			opts:      DownloadOptions{SHA256: checksum},
+			expRanges: []string{""},
+		},
+		"sha256 mismatch": {
+			path:      "/file",
+			opts:      DownloadOptions{SHA256: "00"},
+			expErr:    ErrChecksumMismatch,
+			expRanges: []string{""},
+		},
+		"content-md5": {
+			path:      "/md5",
+			opts:      DownloadOptions{ContentMD5: true},
+			expRanges: []string{""},
+		},
+		"content-md5 mismatch": {
+			path:      "/bad-md5",
+			opts:      DownloadOptions{ContentMD5: true},
+			expErr:    ErrChecksumMismatch,
+			expRanges: []string{""},
+		},
+		"interrupted transfer is resumed": {
+			path:      "/interrupted",
+			opts:      DownloadOptions{Resumes: 1, SHA256: checksum},
+			expRanges: []string{"", "bytes=4500-"},
+		},
+		"interrupted transfer without resumes": {
+			path:      "/interrupted",
+			expErr:    errInterrupted,
+			expRanges: []string{""},
+		},
+		"cache and coalescing are bypassed": {
+			path:      "/file",
+			opts:      DownloadOptions{SHA256: checksum},
+			options:   []Option{WithCache(NewMemoryStore(10)), WithCoalescing(), WithMaxBodySize(1000)},
+			expRanges: []string{""},
+		},
+		"truncated buffered body": {
+			path: "/file",
+			options: []Option{WithMaxBodySize(1000), WithMiddleware(func(next Handler) Handler {
+				return func(req *Request) (*Response, error) {
+					res, err := next(req)
+					if err == nil {
+						_, _ = res.BodyE()
+					}
+
+					return res, err
+				}
+			})},
+			expErr:    ErrBodyTooLarge,
+			expRanges: []string{""},
+		},
+		"part file of earlier attempt is resumed": {
+			path:      "/file",
+			opts:      DownloadOptions{SHA256: checksum},
+			partial:   true,
+			expRanges: []string{"bytes=100-"},
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			var (
+				requests atomic.Int32
+				ranges   []string
+			)
+
+			server := setupDownloadServer(&requests, &ranges)
+			defer server.Close()
+
+			c, err := NewClient(server.URL, tc.options...)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			dst := filepath.Join(t.TempDir(), "data.osm.pbf")
+
+			if tc.partial {
+				if err := os.WriteFile(dst+partSuffix, downloadData[:100], 0o600); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := os.WriteFile(dst+validatorSuffix, []byte(`"v1"`), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var progress int64
+			tc.opts.Progress = func(transferred, _ int64) { progress = transferred }
+
+			_, err = c.NR().Download(t.Context(), tc.path, dst, tc.opts)
+			if !errors.Is(err, tc.expErr) {
+				t.Fatalf("expected err: %v, got: %v", tc.expErr, err)
+			}
+
+			if len(ranges) != len(tc.expRanges) || (len(ranges) > 0 && ranges[len(ranges)-1] != tc.expRanges[len(tc.expRanges)-1]) {
+				t.Errorf("expected ranges: %q, got: %q", tc.expRanges, ranges)
+			}
+
+			got, readErr := os.ReadFile(dst)
+
+			if tc.expErr != nil {
+				if readErr == nil {
+					t.Error("expected no file on error")
+				}
+
+				return
+			}
+
+			if !bytes.Equal(got, downloadData) {
+				t.Errorf("expected downloaded data, got %d bytes", len(got))
+			}
+
+			if progress != int64(len(downloadData)) {
+				t.Errorf("expected progress %d, got: %d", len(downloadData), progress)
+			}
+
+			if _, err := os.Stat(dst + partSuffix); !os.IsNotExist(err) {
+				t.Error("expected part file to be renamed")
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -0,0 +1,110 @@
+package rip
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// maxErrorBodySize bounds the body snippet kept by HTTPError.
+const maxErrorBodySize = 4 << 10
+
+const contentTypeProblem = "application/problem+json"
+
+// HTTPError is returned by Execute for 4xx and 5xx responses when the
+// client is created WithErrorOnStatus.
+type HTTPError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Header     http.Header
+	// Body holds at most the first 4KiB of the response body.
+	Body []byte
+	// Problem is set for application/problem+json responses.
+	Problem *Problem
+}
+
+// Error returns a description of the failed request.
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+
+	if e.Problem != nil {
+		if e.Problem.Title != "" {
+			msg += ": " + e.Problem.Title
+		}
+
+		if e.Problem.Detail != "" {
+			msg += ": " + e.Problem.Detail
+		}
+	}
+
+	return msg
+}
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extensions holds all members that are not defined by RFC 7807.
+	Extensions map[string]any `json:"-"`
+}
+
+// UnmarshalJSON decodes a problem, collecting unknown members into Extensions.
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	type problem Problem
+
+	if err := json.Unmarshal(b, (*problem)(p)); err != nil {
+		return err
+	}
+
+	members := map[string]any{}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+
+	for _, k := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, k)
+	}
+
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+
+	return nil
+}
+
+// WithErrorOnStatus makes Execute return an *HTTPError for 4xx and 5xx responses.
+func WithErrorOnStatus() Option {
+	return func(c *Client) {
+		c.options.ErrorOnStatus = true
+	}
+}
+
+// newHTTPError creates an HTTPError for res. The body snippet is peeked,
+// so the response body can still be read in full.
+func newHTTPError(res *Response) *HTTPError {
+	e := &HTTPError{
+		StatusCode: res.StatusCode(),
+		Header:     res.Header(),
+		Body:       res.peek(maxErrorBodySize),
+	}
+
+	if res.Request != nil && res.Request.rawRequest != nil {
+		e.Method = res.Request.rawRequest.Method
+		e.URL = res.Request.rawRequest.URL.String()
+	}
+
+	mt, _, err := mime.ParseMediaType(res.Header().Get("Content-Type"))
+	if err == nil && mt == contentTypeProblem {
+		p := &Problem{}
+		if err := json.Unmarshal(e.Body, p); err == nil {
+			e.Problem = p
+		}
+	}
+
+	return e
+}
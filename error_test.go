@@ -0,0 +1,113 @@
+package rip
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithErrorOnStatus(t *testing.T) {
+	problem := `{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30.","balance":30}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/problem":
+			w.Header().Set("Content-Type", contentTypeProblem)
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, problem)
+		case "/large":
+			w.Header().Set("Content-Type", contentTypeTEXT)
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, strings.Repeat("x", 2*maxErrorBodySize))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithErrorOnStatus())
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	t.Run("success", func(t *testing.T) {
+		res, err := c.NR().Execute(t.Context(), http.MethodGet, "/ok")
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+		res.Close()
+	})
+
+	t.Run("problem", func(t *testing.T) {
+		res, err := c.NR().Execute(t.Context(), http.MethodGet, "/problem")
+		defer res.Close()
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("expected *HTTPError, got: %v", err)
+		}
+
+		if httpErr.StatusCode != http.StatusForbidden || httpErr.Method != http.MethodGet {
+			t.Errorf("unexpected error %+v", httpErr)
+		}
+
+		if httpErr.URL != server.URL+"/problem" {
+			t.Errorf("expected URL %s, got: %s", server.URL+"/problem", httpErr.URL)
+		}
+
+		if httpErr.Problem == nil || httpErr.Problem.Detail != "Your current balance is 30." {
+			t.Fatalf("expected problem to be decoded, got: %+v", httpErr.Problem)
+		}
+
+		if httpErr.Problem.Extensions["balance"] != float64(30) {
+			t.Errorf("expected balance extension, got: %v", httpErr.Problem.Extensions)
+		}
+
+		if res.String() != problem {
+			t.Errorf("expected body to remain readable, got: %s", res.String())
+		}
+	})
+
+	t.Run("bounded body", func(t *testing.T) {
+		res, err := c.NR().Execute(t.Context(), http.MethodGet, "/large")
+		defer res.Close()
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("expected *HTTPError, got: %v", err)
+		}
+
+		if len(httpErr.Body) != maxErrorBodySize {
+			t.Errorf("expected body snippet of %d bytes, got: %d", maxErrorBodySize, len(httpErr.Body))
+		}
+
+		if res.buffered {
+			t.Errorf("expected body to remain streamable")
+		}
+
+		if b, _ := io.ReadAll(res.RawBody()); len(b) != 2*maxErrorBodySize {
+			t.Errorf("expected full body to remain readable, got: %d bytes", len(b))
+		}
+	})
+	t.Run("short-circuited response without request", func(t *testing.T) {
+		res, err := c.NR().
+			Use(func(next Handler) Handler {
+				return func(req *Request) (*Response, error) {
+					return NewResponse(nil, &http.Response{StatusCode: http.StatusInternalServerError}), nil
+				}
+			}).
+			Execute(t.Context(), http.MethodGet, "/ok")
+		defer res.Close()
+
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("expected *HTTPError, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package rip
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArrayFormat controls how slices are encoded in query and form values.
+type ArrayFormat int
+
+const (
+	// ArrayRepeat repeats the key: k=a&k=b
+	ArrayRepeat ArrayFormat = iota
+	// ArrayCSV joins the values: k=a,b
+	ArrayCSV
+	// ArrayBrackets repeats the key with brackets: k[]=a&k[]=b
+	ArrayBrackets
+)
+
+// ValueFormatter formats values of custom types for query, form and path
+// parameters. It reports false to fall back to the default formatting.
+type ValueFormatter func(v any) (string, bool)
+
+// WithArrayFormat sets how slices are encoded in query and form values.
+func WithArrayFormat(format ArrayFormat) Option {
+	return func(c *Client) {
+		c.options.ArrayFormat = format
+	}
+}
+
+// WithValueFormatter sets a formatter for custom types in query, form and
+// path parameters. It is consulted before the default formatting.
+func WithValueFormatter(formatter ValueFormatter) Option {
+	return func(c *Client) {
+		c.options.ValueFormatter = formatter
+	}
+}
+
+// valueEncoder formats parameter values.
+type valueEncoder struct {
+	arrays ArrayFormat
+	custom ValueFormatter
+}
+
+// encoder returns the value encoder configured on the client.
+func (r *Request) encoder() valueEncoder {
+	if r.client == nil {
+		return valueEncoder{}
+	}
+
+	return valueEncoder{
+		arrays: r.client.options.ArrayFormat,
+		custom: r.client.options.ValueFormatter,
+	}
+}
+
+// values formats params as url.Values. Values that cannot be
+// formatted are skipped.
+func (e valueEncoder) values(params map[string]any) url.Values {
+	values := url.Values{}
+
+	for k, v := range params {
+		e.add(values, k, v)
+	}
+
+	return values
+}
+
+// add formats v and adds it to values under key.
+func (e valueEncoder) add(values url.Values, key string, v any) {
+	if s, ok := e.scalar(v); ok {
+		values.Add(key, s)
+		return
+	}
+
+	list, ok := e.list(v)
+	if !ok {
+		return
+	}
+
+	e.addList(values, key, list, e.arrays)
+}
+
+// addList adds list to values under key using the given array format.
+func (e valueEncoder) addList(values url.Values, key string, list []string, format ArrayFormat) {
+	if len(list) == 0 {
+		return
+	}
+
+	switch format {
+	case ArrayCSV:
+		values.Add(key, strings.Join(list, ","))
+	case ArrayBrackets:
+		for _, s := range list {
+			values.Add(key+"[]", s)
+		}
+	case ArrayRepeat:
+		for _, s := range list {
+			values.Add(key, s)
+		}
+	}
+}
+
+// path formats v as path segment, joining slices with commas.
+func (e valueEncoder) path(v any) (string, bool) {
+	if s, ok := e.scalar(v); ok {
+		return s, true
+	}
+
+	list, ok := e.list(v)
+	if !ok {
+		return "", false
+	}
+
+	return strings.Join(list, ","), true
+}
+
+// scalar formats a single value.
+func (e valueEncoder) scalar(v any) (string, bool) { //nolint: cyclop
+	if e.custom != nil {
+		if s, ok := e.custom(v); ok {
+			return s, true
+		}
+	}
+
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case []byte:
+		return string(val), true
+	case bool:
+		return strconv.FormatBool(val), true
+	case int:
+		return strconv.Itoa(val), true
+	case int8:
+		return strconv.FormatInt(int64(val), 10), true
+	case int16:
+		return strconv.FormatInt(int64(val), 10), true
+	case int32:
+		return strconv.FormatInt(int64(val), 10), true
+	case int64:
+		return strconv.FormatInt(val, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(val), 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(val), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(val), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(val), 10), true
+	case uint64:
+		return strconv.FormatUint(val, 10), true
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case time.Time:
+		return val.Format(time.RFC3339Nano), true
+	case *time.Time:
+		return deref(e, val)
+	case encoding.TextMarshaler:
+		return method(func() (string, error) {
+			b, err := val.MarshalText()
+			return string(b), err
+		})
+	case fmt.Stringer:
+		return method(func() (string, error) { return val.String(), nil })
+	}
+
+	return e.pointer(v)
+}
+
+// pointer formats pointers to basic types. nil pointers are skipped.
+func (e valueEncoder) pointer(v any) (string, bool) { //nolint: cyclop
+	switch val := v.(type) {
+	case *string:
+		return deref(e, val)
+	case *bool:
+		return deref(e, val)
+	case *int:
+		return deref(e, val)
+	case *int8:
+		return deref(e, val)
+	case *int16:
+		return deref(e, val)
+	case *int32:
+		return deref(e, val)
+	case *int64:
+		return deref(e, val)
+	case *uint:
+		return deref(e, val)
+	case *uint8:
+		return deref(e, val)
+	case *uint16:
+		return deref(e, val)
+	case *uint32:
+		return deref(e, val)
+	case *uint64:
+		return deref(e, val)
+	case *float32:
+		return deref(e, val)
+	case *float64:
+		return deref(e, val)
+	}
+
+	return "", false
+}
+
+// method formats a value using one of its methods. Pointers implement the
+// methods of their value receivers, which panic if the pointer is nil, so
+// that these values are skipped.
+func method(format func() (string, error)) (s string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			s, ok = "", false
+		}
+	}()
+
+	s, err := format()
+
+	return s, err == nil
+}
+
+// list formats slices of basic types.
+func (e valueEncoder) list(v any) ([]string, bool) { //nolint: cyclop
+	switch val := v.(type) {
+	case []string:
+		return val, true
+	case []any:
+		return each(e, val)
+	case []bool:
+		return each(e, val)
+	case []int:
+		return each(e, val)
+	case []int8:
+		return each(e, val)
+	case []int16:
+		return each(e, val)
+	case []int32:
+		return each(e, val)
+	case []int64:
+		return each(e, val)
+	case []uint:
+		return each(e, val)
+	case []uint16:
+		return each(e, val)
+	case []uint32:
+		return each(e, val)
+	case []uint64:
+		return each(e, val)
+	case []float32:
+		return each(e, val)
+	case []float64:
+		return each(e, val)
+	case []time.Time:
+		return each(e, val)
+	}
+
+	return nil, false
+}
+
+func deref[T any](e valueEncoder, p *T) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+
+	return e.scalar(*p)
+}
+
+func each[T any](e valueEncoder, s []T) ([]string, bool) {
+	list := make([]string, 0, len(s))
+
+	for _, v := range s {
+		f, ok := e.scalar(v)
+		if !ok {
+			return nil, false
+		}
+
+		list = append(list, f)
+	}
+
+	return list, true
+}
@@ -0,0 +1,104 @@
+package rip
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+type testID int
+
+type testStringer struct{}
+
+func (testStringer) String() string { return "stringer" }
+
+func TestValueEncoder(t *testing.T) {
+	type tcase struct {
+		encoder  valueEncoder
+		params   map[string]any
+		expected string
+	}
+
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	s := "pointer"
+	var nilInt *int
+
+	tests := map[string]tcase{
+		"numeric kinds": {
+			params: map[string]any{
+				"a": int32(1), "b": int64(2), "c": uint8(3), "d": uint64(4),
+				"e": float32(1.1), "f": 0.1, "g": 1e21,
+			},
+			expected: "a=1&b=2&c=3&d=4&e=1.1&f=0.1&g=1000000000000000000000",
+		},
+		"time stringer and text marshaler": {
+			params: map[string]any{
+				"at": ts,
+				"d":  time.Second,
+				"ip": net.ParseIP("127.0.0.1"),
+			},
+			expected: "at=2024-05-01T12%3A30%3A00Z&d=1s&ip=127.0.0.1",
+		},
+		"pointers": {
+			params:   map[string]any{"s": &s, "n": nilInt},
+			expected: "s=pointer",
+		},
+		"nil pointers with value methods": {
+			params: map[string]any{
+				"s": (*testStringer)(nil), "t": (*time.Time)(nil), "ip": (*net.IP)(nil),
+			},
+			expected: "",
+		},
+		"slices repeated": {
+			params:   map[string]any{"id": []int{1, 2}},
+			expected: "id=1&id=2",
+		},
+		"slices csv": {
+			encoder:  valueEncoder{arrays: ArrayCSV},
+			params:   map[string]any{"id": []int64{1, 2}},
+			expected: "id=1%2C2",
+		},
+		"slices brackets": {
+			encoder:  valueEncoder{arrays: ArrayBrackets},
+			params:   map[string]any{"id": []any{"a", 2}},
+			expected: "id%5B%5D=a&id%5B%5D=2",
+		},
+		"custom formatter": {
+			encoder: valueEncoder{custom: func(v any) (string, bool) {
+				id, ok := v.(testID)
+				if !ok {
+					return "", false
+				}
+				return "id-" + string(rune('0'+id)), true
+			}},
+			params:   map[string]any{"id": testID(7), "n": 1},
+			expected: "id=id-7&n=1",
+		},
+		"unsupported": {
+			params:   map[string]any{"m": map[string]any{}},
+			expected: "",
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+
+			got := tc.encoder.values(tc.params).Encode()
+			if got != tc.expected {
+				t.Errorf("expected: %v, got: %v", tc.expected, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestValueEncoderPath(t *testing.T) {
+	got, ok := valueEncoder{}.path([]string{"a", "b"})
+	if !ok || got != "a,b" {
+		t.Errorf("expected a,b, got: %v", got)
+	}
+}
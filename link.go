@@ -0,0 +1,69 @@
+package rip
+
+import "strings"
+
+type link struct {
+	url string
+	rel string
+}
+
+// parseLinks parses the value of an RFC 8288 Link header,
+// e.g. <https://x.io/?page=2>; rel="next", <https://x.io/?page=9>; rel=last
+func parseLinks(h string) []link {
+	var links []link
+
+	for {
+		start := strings.IndexByte(h, '<')
+		if start == -1 {
+			return links
+		}
+
+		end := strings.IndexByte(h[start:], '>')
+		if end == -1 {
+			return links
+		}
+
+		l := link{url: h[start+1 : start+end]}
+		h = h[start+end+1:]
+
+		params, rest := splitLinkParams(h)
+		h = rest
+
+		for _, param := range params {
+			k, v, ok := strings.Cut(param, "=")
+			if ok && strings.EqualFold(strings.TrimSpace(k), "rel") {
+				l.rel = strings.Trim(strings.TrimSpace(v), `"`)
+			}
+		}
+
+		links = append(links, l)
+	}
+}
+
+// splitLinkParams splits the ;-separated params of a link value up to
+// the next link, respecting quoted strings.
+func splitLinkParams(h string) ([]string, string) {
+	var (
+		params []string
+		quoted bool
+		start  int
+	)
+
+	for i := 0; i < len(h); i++ {
+		switch h[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				params = append(params, h[start:i])
+				start = i + 1
+			}
+		case ',':
+			if !quoted {
+				return append(params, h[start:i]), h[i+1:]
+			}
+		}
+	}
+
+	return append(params, h[start:]), ""
+}
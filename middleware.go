@@ -0,0 +1,74 @@
+package rip
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// Handler executes a request and returns its response.
+type Handler func(req *Request) (*Response, error)
+
+// Middleware wraps a Handler, e.g. to sign, log or short-circuit requests.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware registers middleware for all requests of the client.
+// The first middleware registered is the outermost.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// Use registers middleware for this request. It runs inside the
+// client middleware, the first one registered being the outermost.
+func (r *Request) Use(middleware ...Middleware) *Request {
+	r.middleware = append(r.middleware, middleware...)
+
+	return r
+}
+
+// Context returns the context of the request being executed.
+func (r *Request) Context() context.Context {
+	if r.rawRequest == nil {
+		return context.Background()
+	}
+
+	return r.rawRequest.Context()
+}
+
+// RawRequest returns the underlying *http.Request built by Execute.
+// Middleware may mutate it before calling next.
+func (r *Request) RawRequest() *http.Request {
+	return r.rawRequest
+}
+
+// handler composes client and request middleware around authentication,
+// the cache, request coalescing and Client.execute. Requests are
+// authenticated first, so that the cache and coalescing tell their
+// credentials apart.
+func (r *Request) handler() Handler {
+	h := r.client.execute
+
+	if r.client.flights != nil {
+		h = r.client.flights.handler(h)
+	}
+
+	if r.client.cache != nil {
+		h = r.client.cache.handler(h)
+	}
+
+	if r.authenticator() != nil {
+		h = authHandler(h)
+	}
+
+	for _, m := range slices.Backward(r.middleware) {
+		h = m(h)
+	}
+
+	for _, m := range slices.Backward(r.client.middleware) {
+		h = m(h)
+	}
+
+	return h
+}
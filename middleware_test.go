@@ -0,0 +1,94 @@
+package rip
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(r.Header.Get("X-Signature")))
+	}))
+	defer server.Close()
+
+	var order []string
+
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *Request) (*Response, error) {
+				order = append(order, name+" before")
+				res, err := next(req)
+				order = append(order, name+" after")
+				return res, err
+			}
+		}
+	}
+
+	sign := func(next Handler) Handler {
+		return func(req *Request) (*Response, error) {
+			req.RawRequest().Header.Set("X-Signature", "signed")
+			return next(req)
+		}
+	}
+
+	c, err := NewClient(server.URL, WithMiddleware(trace("client1"), trace("client2")))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	res, err := c.NR().
+		Use(trace("request"), sign).
+		Execute(t.Context(), http.MethodGet, "/")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if got := res.String(); got != "signed" {
+		t.Errorf("expected middleware to sign request, got: %q", got)
+	}
+
+	expected := []string{
+		"client1 before", "client2 before", "request before",
+		"request after", "client2 after", "client1 after",
+	}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected order %v, got: %v", expected, order)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("expected request not to reach the server")
+	}))
+	defer server.Close()
+
+	cached := func(_ Handler) Handler {
+		return func(req *Request) (*Response, error) {
+			return NewResponse(req, &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("cached")),
+			}), nil
+		}
+	}
+
+	c, err := NewClient(server.URL, WithMiddleware(cached))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	res, err := c.NR().Execute(t.Context(), http.MethodGet, "/")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if got := res.String(); got != "cached" {
+		t.Errorf("expected cached response, got: %q", got)
+	}
+}
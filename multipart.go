@@ -0,0 +1,203 @@
+package rip
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"sync"
+)
+
+// ErrBodyNotRewindable occurs when a one-shot body source is read twice.
+var ErrBodyNotRewindable = errors.New("body source can not be rewound")
+
+// OpenFunc opens a body source, e.g. a file, for reading.
+type OpenFunc func() (io.ReadCloser, error)
+
+type multipartPart struct {
+	name       string
+	filename   string
+	value      string
+	open       OpenFunc
+	rewindable bool
+}
+
+// multipartBody streams a multipart/form-data body through an io.Pipe.
+type multipartBody struct {
+	mu       sync.Mutex
+	boundary string
+	parts    []multipartPart
+}
+
+// SetMultipart switches the request body to multipart/form-data.
+// It is implied by AddFormField, AddFile and AddFileSource.
+func (r *Request) SetMultipart() *Request {
+	if r.multipart == nil {
+		r.multipart = &multipartBody{
+			boundary: multipart.NewWriter(io.Discard).Boundary(),
+		}
+	}
+
+	return r
+}
+
+// AddFormField adds a form field to the multipart body.
+func (r *Request) AddFormField(name, value string) *Request {
+	r.SetMultipart()
+	r.multipart.parts = append(r.multipart.parts, multipartPart{
+		name:       name,
+		value:      value,
+		rewindable: true,
+	})
+
+	return r
+}
+
+// AddFile adds a file to the multipart body, streamed from rd. If rd is
+// an io.Seeker it is rewound on retries, otherwise it can only be sent once.
+// rd is not closed.
+func (r *Request) AddFile(name, filename string, rd io.Reader) *Request {
+	r.SetMultipart()
+
+	part := multipartPart{name: name, filename: filename}
+
+	if s, ok := rd.(io.Seeker); ok {
+		open, err := seekBody(rd, s)
+		if err != nil {
+			r.err = err
+			return r
+		}
+
+		part.open = open
+		part.rewindable = true
+	} else {
+		used := false
+		part.open = func() (io.ReadCloser, error) {
+			if used {
+				return nil, ErrBodyNotRewindable
+			}
+			used = true
+
+			return io.NopCloser(rd), nil
+		}
+	}
+
+	r.multipart.parts = append(r.multipart.parts, part)
+
+	return r
+}
+
+// AddFileSource adds a file to the multipart body, streamed from the
+// reader returned by open. open is called again for every retry and the
+// reader is closed after it has been sent.
+func (r *Request) AddFileSource(name, filename string, open OpenFunc) *Request {
+	r.SetMultipart()
+	r.multipart.parts = append(r.multipart.parts, multipartPart{
+		name:       name,
+		filename:   filename,
+		open:       open,
+		rewindable: true,
+	})
+
+	return r
+}
+
+func (m *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+func (m *multipartBody) rewindable() bool {
+	for _, p := range m.parts {
+		if !p.rewindable {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reader returns a new reader streaming the body. Nothing is written
+// before the first Read.
+func (m *multipartBody) reader() (io.ReadCloser, error) {
+	return pipeReader(&m.mu, m.write), nil
+}
+
+func (m *multipartBody) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(m.boundary); err != nil {
+		return err
+	}
+
+	for _, p := range m.parts {
+		if p.open == nil {
+			if err := mw.WriteField(p.name, p.value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := writeFile(mw, p); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+func writeFile(mw *multipart.Writer, p multipartPart) error {
+	fw, err := mw.CreateFormFile(p.name, p.filename)
+	if err != nil {
+		return err
+	}
+
+	rc, err := p.open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close() //nolint: errcheck
+
+	_, err = io.Copy(fw, rc)
+
+	return err
+}
+
+// pipeReader returns a reader of what write writes to it. write is only
+// started on the first Read and holds mu while it runs.
+func pipeReader(mu *sync.Mutex, write func(w io.Writer) error) io.ReadCloser {
+	return &lazyReader{open: func() io.ReadCloser {
+		pr, pw := io.Pipe()
+
+		go func() {
+			// one writer at a time, a previous attempt might still be
+			// reading from a shared source.
+			mu.Lock()
+			defer mu.Unlock()
+
+			pw.CloseWithError(write(pw))
+		}()
+
+		return pr
+	}}
+}
+
+// lazyReader opens its source on first Read.
+type lazyReader struct {
+	open func() io.ReadCloser
+	rc   io.ReadCloser
+}
+
+func (l *lazyReader) Read(p []byte) (int, error) {
+	if l.rc == nil {
+		l.rc = l.open()
+	}
+
+	return l.rc.Read(p)
+}
+
+func (l *lazyReader) Close() error {
+	if l.rc == nil {
+		return nil
+	}
+
+	return l.rc.Close()
+}
@@ -0,0 +1,111 @@
+package rip
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMultipart(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := attempts.Add(1)
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("attempt %d: expected multipart body, got: %v", n, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if got := r.FormValue("title"); got != "holiday" {
+			t.Errorf("attempt %d: expected field title, got: %q", n, got)
+		}
+
+		for field, exp := range map[string]string{"photo": "JPEG DATA", "doc": "PDF DATA"} {
+			f, h, err := r.FormFile(field)
+			if err != nil {
+				t.Errorf("attempt %d: expected file %s, got: %v", n, field, err)
+				continue
+			}
+
+			b, _ := io.ReadAll(f)
+			if string(b) != exp || h.Filename != field+".bin" {
+				t.Errorf("attempt %d: unexpected file %s: %q", n, h.Filename, b)
+			}
+		}
+
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithRetry(RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	opened := 0
+	res, err := c.NR().
+		AddFormField("title", "holiday").
+		AddFile("photo", "photo.bin", strings.NewReader("JPEG DATA")).
+		AddFileSource("doc", "doc.bin", func() (io.ReadCloser, error) {
+			opened++
+			return io.NopCloser(strings.NewReader("PDF DATA")), nil
+		}).
+		Execute(t.Context(), http.MethodPost, "/upload")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if res.StatusCode() != http.StatusCreated {
+		t.Errorf("expected StatusCode 201, got: %d", res.StatusCode())
+	}
+
+	if opened != 2 {
+		t.Errorf("expected file source to be opened twice, got: %d", opened)
+	}
+}
+
+func TestMultipartOneShot(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithRetry(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	res, err := c.NR().
+		AddFile("file", "file.bin", io.MultiReader(strings.NewReader("data"))).
+		Execute(t.Context(), http.MethodPost, "/upload")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("expected one-shot body not to be retried, got %d attempts", got)
+	}
+}
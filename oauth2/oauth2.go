@@ -0,0 +1,227 @@
+// Package oauth2 fetches OAuth 2.0 access tokens using the client
+// credentials and refresh token grants of RFC 6749 with a rip.Client.
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iwpnd/rip"
+)
+
+// defaultEarlyRefresh is how long before their expiry tokens are refreshed.
+const defaultEarlyRefresh = 30 * time.Second
+
+// ErrNoAccessToken occurs when the token endpoint responds without access token.
+var ErrNoAccessToken = errors.New("token endpoint returned no access token")
+
+// AuthStyle is how the client authenticates at the token endpoint.
+type AuthStyle int
+
+const (
+	// AuthStyleBasic sends the client credentials as Basic auth header.
+	AuthStyleBasic AuthStyle = iota
+	// AuthStyleBody sends the client credentials as form parameters.
+	AuthStyleBody
+)
+
+// Config of a client at an OAuth 2.0 token endpoint.
+type Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	AuthStyle    AuthStyle
+	Scopes       []string
+	// Audience is sent as audience parameter, if set.
+	Audience string
+	// Params are additional parameters sent to the token endpoint.
+	Params url.Values
+	// EarlyRefresh is how long before their expiry tokens are refreshed.
+	// Defaults to 30s.
+	EarlyRefresh time.Duration
+	// Client calls the token endpoint, defaults to a client for TokenURL.
+	// It must not be authenticated with a token source of this Config.
+	Client *rip.Client
+}
+
+// Error is an error response of the token endpoint, see RFC 6749 5.2.
+type Error struct {
+	StatusCode  int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+	URI         string `json:"error_uri"`
+}
+
+// Error returns the error code and description.
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("oauth2: %s (status %d)", e.Code, e.StatusCode)
+	}
+
+	return fmt.Sprintf("oauth2: %s: %s (status %d)", e.Code, e.Description, e.StatusCode)
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// WithClientCredentials authenticates all requests of the client with
+// tokens of the client credentials grant.
+func WithClientCredentials(cfg Config) rip.Option {
+	return rip.WithAuth(rip.NewTokenAuth(cfg.ClientCredentials(), cfg.earlyRefresh()))
+}
+
+// WithRefreshToken authenticates all requests of the client with tokens
+// of the refresh token grant.
+func WithRefreshToken(cfg Config, refreshToken string) rip.Option {
+	return rip.WithAuth(rip.NewTokenAuth(cfg.RefreshToken(refreshToken), cfg.earlyRefresh()))
+}
+
+// ClientCredentials returns a token source for the client credentials
+// grant, see RFC 6749 4.4.
+func (c Config) ClientCredentials() rip.TokenSource {
+	client, clientErr := c.client()
+
+	return rip.TokenSourceFunc(func(ctx context.Context) (*rip.Token, error) {
+		if clientErr != nil {
+			return nil, clientErr
+		}
+
+		t, _, err := c.fetch(ctx, client, url.Values{"grant_type": {"client_credentials"}})
+
+		return t, err
+	})
+}
+
+// RefreshToken returns a token source for the refresh token grant, see
+// RFC 6749 6. Refresh tokens rotated by the server are used subsequently.
+func (c Config) RefreshToken(refreshToken string) rip.TokenSource {
+	var mu sync.Mutex
+
+	client, clientErr := c.client()
+
+	return rip.TokenSourceFunc(func(ctx context.Context) (*rip.Token, error) {
+		if clientErr != nil {
+			return nil, clientErr
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		t, rotated, err := c.fetch(ctx, client, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {refreshToken},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if rotated != "" {
+			refreshToken = rotated
+		}
+
+		return t, nil
+	})
+}
+
+// fetch requests a token and returns it along with the refresh token.
+func (c Config) fetch(ctx context.Context, client *rip.Client, params url.Values) (*rip.Token, string, error) {
+	form := url.Values{}
+	for k, v := range c.Params {
+		form[k] = v
+	}
+
+	for k, v := range params {
+		form[k] = v
+	}
+
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	if c.Audience != "" {
+		form.Set("audience", c.Audience)
+	}
+
+	req := client.NR().SetHeader("Accept", "application/json")
+
+	switch c.AuthStyle {
+	case AuthStyleBody:
+		form.Set("client_id", c.ClientID)
+
+		if c.ClientSecret != "" {
+			form.Set("client_secret", c.ClientSecret)
+		}
+	case AuthStyleBasic:
+		// credentials are form-encoded first, see RFC 6749 2.3.1.
+		req.SetAuth(rip.BasicAuth{
+			Username: url.QueryEscape(c.ClientID),
+			Password: url.QueryEscape(c.ClientSecret),
+		})
+	}
+
+	var (
+		tr     tokenResponse
+		oauthE Error
+	)
+
+	res, err := req.
+		SetFormData(form).
+		SetResult(&tr).
+		SetErrorResult(&oauthE).
+		Execute(ctx, http.MethodPost, c.TokenURL)
+
+	// error responses are handled below, also without WithErrorOnStatus.
+	var httpErr *rip.HTTPError
+	if err != nil && !errors.As(err, &httpErr) {
+		return nil, "", err
+	}
+	defer res.Close() //nolint: errcheck
+
+	if res.IsError() {
+		oauthE.StatusCode = res.StatusCode()
+		return nil, "", &oauthE
+	}
+
+	if tr.AccessToken == "" {
+		return nil, "", ErrNoAccessToken
+	}
+
+	t := &rip.Token{AccessToken: tr.AccessToken, TokenType: tr.TokenType}
+
+	// token types are case-insensitive, some servers require Bearer.
+	if strings.EqualFold(t.TokenType, "bearer") {
+		t.TokenType = "Bearer"
+	}
+
+	if tr.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+
+	return t, tr.RefreshToken, nil
+}
+
+func (c Config) client() (*rip.Client, error) {
+	if c.Client != nil {
+		return c.Client, nil
+	}
+
+	return rip.NewClient(c.TokenURL)
+}
+
+func (c Config) earlyRefresh() time.Duration {
+	if c.EarlyRefresh <= 0 {
+		return defaultEarlyRefresh
+	}
+
+	return c.EarlyRefresh
+}
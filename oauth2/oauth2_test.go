@@ -0,0 +1,232 @@
+package oauth2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/iwpnd/rip"
+)
+
+type tokenServer struct {
+	requests  atomic.Int32
+	form      url.Values
+	basicUser string
+	basicPass string
+	expiresIn int64
+}
+
+func (ts *tokenServer) handler(w http.ResponseWriter, r *http.Request) {
+	n := ts.requests.Add(1)
+
+	_ = r.ParseForm()
+	ts.form = r.PostForm
+	ts.basicUser, ts.basicPass, _ = r.BasicAuth()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	// basic auth credentials are form-encoded, see RFC 6749 2.3.1.
+	clientID := r.PostForm.Get("client_id")
+	if clientID == "" {
+		clientID, _ = url.QueryUnescape(ts.basicUser)
+	}
+
+	if clientID != "client id" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid_client","error_description":"unknown client"}`)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token":  fmt.Sprintf("token-%d", n),
+		"token_type":    "bearer",
+		"expires_in":    ts.expiresIn,
+		"refresh_token": fmt.Sprintf("refresh-%d", n),
+	})
+}
+
+func TestClientCredentials(t *testing.T) {
+	type tcase struct {
+		cfg        Config
+		expForm    url.Values
+		expBasic   [2]string
+		expErrCode string
+	}
+
+	tests := map[string]tcase{
+		"basic auth": {
+			cfg: Config{
+				ClientID:     "client id",
+				ClientSecret: "s&cret",
+				Scopes:       []string{"read", "write"},
+				Audience:     "https://api.example.com",
+			},
+			expForm: url.Values{
+				"grant_type": {"client_credentials"},
+				"scope":      {"read write"},
+				"audience":   {"https://api.example.com"},
+			},
+			expBasic: [2]string{"client+id", "s%26cret"},
+		},
+		"body auth": {
+			cfg: Config{
+				ClientID:     "client id",
+				ClientSecret: "secret",
+				AuthStyle:    AuthStyleBody,
+				Params:       url.Values{"resource": {"blog"}},
+			},
+			expForm: url.Values{
+				"grant_type":    {"client_credentials"},
+				"client_id":     {"client id"},
+				"client_secret": {"secret"},
+				"resource":      {"blog"},
+			},
+		},
+		"error response": {
+			cfg: Config{
+				ClientID:  "unknown",
+				AuthStyle: AuthStyleBody,
+			},
+			expErrCode: "invalid_client",
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			ts := &tokenServer{expiresIn: 3600}
+
+			server := httptest.NewServer(http.HandlerFunc(ts.handler))
+			defer server.Close()
+
+			tc.cfg.TokenURL = server.URL + "/token"
+
+			token, err := tc.cfg.ClientCredentials().Token(t.Context())
+
+			var oauthErr *Error
+			if tc.expErrCode != "" {
+				if !errors.As(err, &oauthErr) || oauthErr.Code != tc.expErrCode || oauthErr.StatusCode != http.StatusUnauthorized {
+					t.Fatalf("expected oauth2 error %s, got: %v", tc.expErrCode, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+
+			if token.AccessToken != "token-1" || token.TokenType != "Bearer" {
+				t.Errorf("expected Bearer token-1, got: %+v", token)
+			}
+
+			if d := time.Until(token.Expiry); d < 59*time.Minute || d > time.Hour {
+				t.Errorf("expected expiry in an hour, got: %v", d)
+			}
+
+			if ts.form.Encode() != tc.expForm.Encode() {
+				t.Errorf("expected form: %s, got: %s", tc.expForm.Encode(), ts.form.Encode())
+			}
+
+			if tc.expBasic != [2]string{} && [2]string{ts.basicUser, ts.basicPass} != tc.expBasic {
+				t.Errorf("expected basic auth: %v, got: %v", tc.expBasic, [2]string{ts.basicUser, ts.basicPass})
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	ts := &tokenServer{expiresIn: 3600}
+
+	server := httptest.NewServer(http.HandlerFunc(ts.handler))
+	defer server.Close()
+
+	src := Config{TokenURL: server.URL, ClientID: "client id", AuthStyle: AuthStyleBody}.RefreshToken("refresh-0")
+
+	for i := range 2 {
+		if _, err := src.Token(t.Context()); err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		if got, want := ts.form.Get("refresh_token"), fmt.Sprintf("refresh-%d", i); got != want {
+			t.Errorf("expected rotated refresh token %s, got: %s", want, got)
+		}
+
+		if ts.form.Get("grant_type") != "refresh_token" {
+			t.Errorf("expected refresh_token grant, got: %s", ts.form.Get("grant_type"))
+		}
+	}
+}
+
+func TestWithClientCredentials(t *testing.T) {
+	type tcase struct {
+		expiresIn   int64
+		expRequests int32
+	}
+
+	tests := map[string]tcase{
+		"token is cached": {
+			expiresIn:   3600,
+			expRequests: 1,
+		},
+		"token is refreshed early": {
+			expiresIn:   10,
+			expRequests: 3,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			ts := &tokenServer{expiresIn: tc.expiresIn}
+
+			tokenServer := httptest.NewServer(http.HandlerFunc(ts.handler))
+			defer tokenServer.Close()
+
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, r.Header.Get("Authorization"))
+			}))
+			defer api.Close()
+
+			c, err := rip.NewClient(api.URL, WithClientCredentials(Config{
+				TokenURL: tokenServer.URL,
+				ClientID: "client id",
+			}))
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			for range 3 {
+				res, err := c.NR().Execute(t.Context(), http.MethodGet, "/")
+				if err != nil {
+					t.Fatalf("expected err to be nil, got: %v", err)
+				}
+
+				if got := res.String(); got != fmt.Sprintf("Bearer token-%d", ts.requests.Load()) {
+					t.Errorf("expected latest token, got: %s", got)
+				}
+
+				res.Close() //nolint: errcheck
+			}
+
+			if got := ts.requests.Load(); got != tc.expRequests {
+				t.Errorf("expected %d token requests, got: %d", tc.expRequests, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
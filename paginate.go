@@ -0,0 +1,256 @@
+package rip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrMaxPages occurs when there are more pages than the pagination allows.
+var ErrMaxPages = errors.New("maximum number of pages reached")
+
+// Paginator determines the request for the page following a response.
+type Paginator interface {
+	// Next prepares req for the page following res and returns its path.
+	// It reports false if res is the last page.
+	Next(req *Request, res *Response) (string, bool, error)
+}
+
+// Paginate executes req for path and all following pages determined by p.
+// Each response is closed once the loop body for it returns. maxPages < 1
+// disables the guard, otherwise ErrMaxPages is yielded if there are more.
+// All pages are rate limited by the path template of the first.
+func Paginate(
+	ctx context.Context,
+	req *Request,
+	method, path string,
+	p Paginator,
+	maxPages int,
+) iter.Seq2[*Response, error] {
+	return func(yield func(*Response, error) bool) {
+		defer func() { req.limitKey = "" }()
+
+		for page := 1; ; page++ {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			res, err := req.Execute(ctx, method, path)
+			if err != nil {
+				yield(res, err)
+
+				if res != nil {
+					res.Close() //nolint: errcheck
+				}
+
+				return
+			}
+
+			if page == 1 {
+				req.limitKey = req.template
+			}
+
+			next, more, err := p.Next(req, res)
+
+			cont := yield(res, nil)
+			res.Close() //nolint: errcheck
+
+			switch {
+			case !cont:
+				return
+			case err != nil:
+				yield(nil, err)
+				return
+			case !more:
+				return
+			case maxPages > 0 && page >= maxPages:
+				yield(nil, ErrMaxPages)
+				return
+			}
+
+			path = next
+		}
+	}
+}
+
+// PaginateAs is like Paginate, but decodes each page into T.
+func PaginateAs[T any](
+	ctx context.Context,
+	req *Request,
+	method, path string,
+	p Paginator,
+	maxPages int,
+) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for res, err := range Paginate(ctx, req, method, path, p, maxPages) {
+			var v T
+			if err == nil {
+				err = res.Decode(&v)
+			}
+
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// LinkPaginator follows RFC 8288 Link headers with the relation Rel,
+// defaulting to "next".
+type LinkPaginator struct {
+	Rel string
+}
+
+// Next returns the URL of the next link.
+func (p LinkPaginator) Next(req *Request, res *Response) (string, bool, error) {
+	rel := p.Rel
+	if rel == "" {
+		rel = "next"
+	}
+
+	next, ok := res.Links()[rel]
+	if !ok {
+		return "", false, nil
+	}
+
+	// the link carries all query and path parameters.
+	req.Query = nil
+	req.Params = nil
+
+	return next, true, nil
+}
+
+// CursorPaginator reads the cursor of the next page from the JSON field
+// Field (dot separated, e.g. meta.next_cursor) and sends it as query
+// parameter Param. A missing, null or empty cursor ends the pagination.
+type CursorPaginator struct {
+	Field string
+	Param string
+}
+
+// Next sets the cursor query parameter.
+func (p CursorPaginator) Next(req *Request, res *Response) (string, bool, error) {
+	v, ok, err := jsonField(res, p.Field)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	cursor, ok := req.encoder().scalar(v)
+	if !ok || cursor == "" {
+		return "", false, nil
+	}
+
+	setQuery(req, p.Param, cursor)
+
+	return req.template, true, nil
+}
+
+// OffsetPaginator increments the query parameter OffsetParam by the number
+// of items in the JSON array at ItemsField (dot separated, empty for a top
+// level array). Pagination ends with an empty page, or a page holding less
+// than Limit items if Limit is set. Limit is sent as LimitParam if both are set.
+type OffsetPaginator struct {
+	OffsetParam string
+	LimitParam  string
+	Limit       int
+	ItemsField  string
+}
+
+// Next sets the offset and limit query parameters.
+func (p OffsetPaginator) Next(req *Request, res *Response) (string, bool, error) {
+	n, err := countItems(res, p.ItemsField)
+	if err != nil || n == 0 || n < p.Limit {
+		return "", false, err
+	}
+
+	offset, _ := strconv.Atoi(req.Query.Get(p.OffsetParam))
+
+	setQuery(req, p.OffsetParam, strconv.Itoa(offset+n))
+
+	if p.LimitParam != "" && p.Limit > 0 {
+		setQuery(req, p.LimitParam, strconv.Itoa(p.Limit))
+	}
+
+	return req.template, true, nil
+}
+
+// PageNumberPaginator increments the query parameter PageParam, starting
+// at 1. Pagination ends with an empty page, or a page holding less than
+// Size items if Size is set.
+type PageNumberPaginator struct {
+	PageParam  string
+	Size       int
+	ItemsField string
+}
+
+// Next sets the page query parameter.
+func (p PageNumberPaginator) Next(req *Request, res *Response) (string, bool, error) {
+	n, err := countItems(res, p.ItemsField)
+	if err != nil || n == 0 || n < p.Size {
+		return "", false, err
+	}
+
+	page, err := strconv.Atoi(req.Query.Get(p.PageParam))
+	if err != nil {
+		page = 1
+	}
+
+	setQuery(req, p.PageParam, strconv.Itoa(page+1))
+
+	return req.template, true, nil
+}
+
+func setQuery(req *Request, key, value string) {
+	if req.Query == nil {
+		req.Query = url.Values{}
+	}
+
+	req.Query.Set(key, value)
+}
+
+// jsonField returns the value at the dot separated field of the JSON body.
+// It reports false if the field does not exist or is null.
+func jsonField(res *Response, field string) (any, bool, error) {
+	b, err := res.BodyE()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, false, err
+	}
+
+	if field != "" {
+		for key := range strings.SplitSeq(field, ".") {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, false, nil
+			}
+
+			v = obj[key]
+		}
+	}
+
+	return v, v != nil, nil
+}
+
+func countItems(res *Response, field string) (int, error) {
+	v, ok, err := jsonField(res, field)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	items, ok := v.([]any)
+	if !ok {
+		return 0, fmt.Errorf("%w: field %q is not an array", ErrUnsupportedValue, field)
+	}
+
+	return len(items), nil
+}
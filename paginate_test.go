@@ -0,0 +1,250 @@
+package rip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func setupPaginationServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		q := r.URL.Query()
+
+		switch r.URL.Path {
+		case "/link", "/users/1/link":
+			page, _ := strconv.Atoi(q.Get("page"))
+			if page < 3 {
+				w.Header().Add("Link", fmt.Sprintf(`<%[1]s?page=%[2]d>; rel="next", <%[1]s?page=3>; rel="last"`, r.URL.Path, page+1))
+			}
+
+			fmt.Fprintf(w, `[%d]`, page)
+		case "/cursor":
+			switch q.Get("cursor") {
+			case "":
+				fmt.Fprint(w, `{"items":[0],"meta":{"next":"a"}}`)
+			case "a":
+				fmt.Fprint(w, `{"items":[1],"meta":{"next":"b"}}`)
+			default:
+				fmt.Fprint(w, `{"items":[2],"meta":{"next":null}}`)
+			}
+		case "/offset":
+			offset, _ := strconv.Atoi(q.Get("offset"))
+
+			items := []int{}
+			for i := offset; i < min(offset+2, 5); i++ {
+				items = append(items, i)
+			}
+
+			fmt.Fprint(w, toJSON(items))
+		case "/page":
+			page, _ := strconv.Atoi(q.Get("page"))
+			if page > 3 {
+				fmt.Fprint(w, `{"data":[]}`)
+				return
+			}
+
+			fmt.Fprintf(w, `{"data":[%d]}`, max(page, 1))
+		}
+	}))
+}
+
+func toJSON(items []int) string {
+	s := "["
+	for i, v := range items {
+		if i > 0 {
+			s += ","
+		}
+
+		s += strconv.Itoa(v)
+	}
+
+	return s + "]"
+}
+
+func TestPaginate(t *testing.T) {
+	type tcase struct {
+		path      string
+		paginator Paginator
+		maxPages  int
+		expected  string
+		expErr    error
+	}
+
+	tests := map[string]tcase{
+		"link header": {
+			path:      "/link",
+			paginator: LinkPaginator{},
+			expected:  "[0][1][2][3]",
+		},
+		"cursor": {
+			path:      "/cursor",
+			paginator: CursorPaginator{Field: "meta.next", Param: "cursor"},
+			expected:  `{"items":[0],"meta":{"next":"a"}}{"items":[1],"meta":{"next":"b"}}{"items":[2],"meta":{"next":null}}`,
+		},
+		"offset": {
+			path:      "/offset",
+			paginator: OffsetPaginator{OffsetParam: "offset", LimitParam: "limit", Limit: 2},
+			expected:  "[0,1][2,3][4]",
+		},
+		"page number": {
+			path:      "/page",
+			paginator: PageNumberPaginator{PageParam: "page", ItemsField: "data"},
+			expected:  `{"data":[1]}{"data":[2]}{"data":[3]}{"data":[]}`,
+		},
+		"max pages": {
+			path:      "/link",
+			paginator: LinkPaginator{},
+			maxPages:  2,
+			expected:  "[0][1]",
+			expErr:    ErrMaxPages,
+		},
+	}
+
+	server := setupPaginationServer()
+	defer server.Close()
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			c, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			var (
+				got    string
+				gotErr error
+			)
+
+			for res, err := range Paginate(t.Context(), c.NR(), http.MethodGet, tc.path, tc.paginator, tc.maxPages) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+
+				got += res.String()
+			}
+
+			if !errors.Is(gotErr, tc.expErr) {
+				t.Errorf("expected err: %v, got: %v", tc.expErr, gotErr)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected: %v, got: %v", tc.expected, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestPaginateLinkTemplate(t *testing.T) {
+	server := setupPaginationServer()
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithStrictPathParams(), WithPathRateLimit("/users/:id/link", 0.001, 4))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	req := c.NR().SetParams(Params{"id": 1})
+
+	var got string
+
+	for res, err := range Paginate(t.Context(), req, http.MethodGet, "/users/:id/link", LinkPaginator{}, 0) {
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		got += res.String()
+	}
+
+	if got != "[0][1][2][3]" {
+		t.Errorf("expected all pages, got: %v", got)
+	}
+
+	if tokens := c.RateLimiter().paths["/users/:id/link"].Tokens(); tokens > 0.1 {
+		t.Errorf("expected every page to take a token of the template, got %v left", tokens)
+	}
+}
+
+func TestPaginateAs(t *testing.T) {
+	server := setupPaginationServer()
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	var pages [][]int
+
+	for page, err := range PaginateAs[[]int](t.Context(), c.NR(), http.MethodGet, "/link", LinkPaginator{}, 0) {
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		pages = append(pages, page)
+		if len(pages) == 2 {
+			break
+		}
+	}
+
+	if fmt.Sprint(pages) != "[[0] [1]]" {
+		t.Errorf("expected to stop after two pages, got: %v", pages)
+	}
+}
+
+func TestPaginateCanceled(t *testing.T) {
+	server := setupPaginationServer()
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	for _, err := range Paginate(ctx, c.NR(), http.MethodGet, "/link", LinkPaginator{}, 0) {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got: %v", err)
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	res := &Response{rawResponse: &http.Response{Header: http.Header{
+		"Link": []string{`<https://x.io/a?page=2>; rel="next prefetch", <https://x.io/a;v=1?page=1>; title="a, b"; rel=prev`},
+	}}}
+
+	links := res.Links()
+	if links["next"] != "https://x.io/a?page=2" || links["prefetch"] != links["next"] {
+		t.Errorf("expected next link, got: %v", links)
+	}
+
+	if links["prev"] != "https://x.io/a;v=1?page=1" {
+		t.Errorf("expected prev link, got: %v", links)
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	c, err := NewClient("http://localhost:0")
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	for _, err := range Paginate(t.Context(), c.NR(), http.MethodGet, "/:missing", LinkPaginator{}, 0) {
+		if !errors.Is(err, ErrMissingPathParam) {
+			t.Errorf("expected ErrMissingPathParam, got: %v", err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package rip
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrMissingPathParam occurs when a path placeholder has no (formattable) param.
+	ErrMissingPathParam = errors.New("missing path parameter")
+	// ErrUnusedPathParam occurs in strict mode when a param has no placeholder.
+	ErrUnusedPathParam = errors.New("unused path parameter")
+)
+
+// WithStrictPathParams makes Execute fail with ErrUnusedPathParam
+// when a param does not match any placeholder of the path.
+func WithStrictPathParams() Option {
+	return func(c *Client) {
+		c.options.StrictPathParams = true
+	}
+}
+
+// expandPath replaces the placeholders of template, either :name at the
+// start of a segment or {name}, with the path escaped params.
+func expandPath(template string, params Params, enc valueEncoder, strict bool) (string, error) {
+	var (
+		b       strings.Builder
+		missing []string
+		used    = map[string]bool{}
+	)
+
+	for i := 0; i < len(template); {
+		name, end, ok := placeholder(template, i)
+		if !ok {
+			b.WriteByte(template[i])
+			i++
+
+			continue
+		}
+
+		used[name] = true
+
+		v, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+			i = end
+
+			continue
+		}
+
+		p, ok := enc.path(v)
+		if !ok || p == "" {
+			missing = append(missing, name)
+			i = end
+
+			continue
+		}
+
+		b.WriteString(url.PathEscape(p))
+		i = end
+	}
+
+	if len(missing) > 0 {
+		return template, fmt.Errorf("%w: %s", ErrMissingPathParam, strings.Join(missing, ", "))
+	}
+
+	if strict {
+		var unused []string
+		for _, k := range slices.Sorted(maps.Keys(params)) {
+			if !used[k] {
+				unused = append(unused, k)
+			}
+		}
+
+		if len(unused) > 0 {
+			return template, fmt.Errorf("%w: %s", ErrUnusedPathParam, strings.Join(unused, ", "))
+		}
+	}
+
+	return b.String(), nil
+}
+
+// placeholder returns the name and end of a placeholder starting at i.
+func placeholder(template string, i int) (string, int, bool) {
+	switch template[i] {
+	case ':':
+		if i > 0 && template[i-1] != '/' {
+			return "", 0, false
+		}
+
+		end := i + 1
+		for end < len(template) && isNameChar(template[end]) {
+			end++
+		}
+
+		if end == i+1 {
+			return "", 0, false
+		}
+
+		return template[i+1 : end], end, true
+	case '{':
+		n := strings.IndexByte(template[i:], '}')
+		if n < 2 {
+			return "", 0, false
+		}
+
+		return template[i+1 : i+n], i + n + 1, true
+	default:
+		return "", 0, false
+	}
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' ||
+		('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z') ||
+		('0' <= c && c <= '9')
+}
@@ -0,0 +1,209 @@
+package rip
+
+import (
+	"fmt"
+	"net/url"
+	"reflect" //nolint: depguard // struct tags can only be read using reflection
+	"strconv"
+	"strings"
+)
+
+// NestingFormat controls how keys of nested structs are encoded.
+type NestingFormat int
+
+const (
+	// NestingDot joins keys with dots: filter.name=x
+	NestingDot NestingFormat = iota
+	// NestingBrackets wraps nested keys in brackets: filter[name]=x
+	NestingBrackets
+)
+
+// WithNestingFormat sets how keys of nested structs are encoded by SetQueryStruct.
+func WithNestingFormat(format NestingFormat) Option {
+	return func(c *Client) {
+		c.options.NestingFormat = format
+	}
+}
+
+// SetQueryStruct adds the fields of the struct v to the query parameters.
+// Fields are named by their `url:"name"` tag, falling back to the field
+// name. Tag options are omitempty to skip zero values and comma, brackets
+// or repeat to override the array format of a slice field. Fields tagged
+// `url:"-"` are skipped, embedded structs are flattened.
+func (r *Request) SetQueryStruct(v any) *Request {
+	if r.Query == nil {
+		r.Query = url.Values{}
+	}
+
+	nesting := NestingDot
+	if r.client != nil {
+		nesting = r.client.options.NestingFormat
+	}
+
+	enc := structEncoder{valueEncoder: r.encoder(), nesting: nesting}
+	if err := enc.encode(r.Query, v); err != nil {
+		r.err = err
+	}
+
+	return r
+}
+
+type structEncoder struct {
+	valueEncoder
+	nesting NestingFormat
+}
+
+type tagOptions struct {
+	omitempty bool
+	arrays    ArrayFormat
+}
+
+func (e structEncoder) encode(values url.Values, v any) error {
+	rv, ok := indirect(reflect.ValueOf(v))
+	if !ok {
+		return nil
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected struct, got %T", ErrUnsupportedValue, v)
+	}
+
+	e.encodeStruct(values, "", rv)
+
+	return nil
+}
+
+func (e structEncoder) encodeStruct(values url.Values, prefix string, rv reflect.Value) {
+	rt := rv.Type()
+
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+
+		tag := f.Tag.Get("url")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts := e.parseTag(tag)
+		fv := rv.Field(i)
+
+		// embedded structs are flattened, even if their type is unexported.
+		if f.Anonymous && name == "" {
+			if ev, ok := indirect(fv); ok && ev.Kind() == reflect.Struct {
+				e.encodeStruct(values, prefix, ev)
+				continue
+			}
+		}
+
+		if !f.IsExported() || (opts.omitempty && fv.IsZero()) {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		e.encodeValue(values, e.key(prefix, name), fv, opts)
+	}
+}
+
+func (e structEncoder) encodeValue(values url.Values, key string, fv reflect.Value, opts tagOptions) {
+	fv, ok := indirect(fv)
+	if !ok {
+		return
+	}
+
+	if s, ok := e.reflectScalar(fv); ok {
+		values.Add(key, s)
+		return
+	}
+
+	switch fv.Kind() { //nolint: exhaustive
+	case reflect.Struct:
+		e.encodeStruct(values, key, fv)
+	case reflect.Slice, reflect.Array:
+		list := make([]string, 0, fv.Len())
+
+		for j := range fv.Len() {
+			ev, ok := indirect(fv.Index(j))
+			if !ok {
+				continue
+			}
+
+			if s, ok := e.reflectScalar(ev); ok {
+				list = append(list, s)
+			}
+		}
+
+		e.addList(values, key, list, opts.arrays)
+	}
+}
+
+// reflectScalar formats fv using the value encoder, falling back to its
+// kind for named basic types.
+func (e structEncoder) reflectScalar(fv reflect.Value) (string, bool) {
+	if s, ok := e.scalar(fv.Interface()); ok {
+		return s, true
+	}
+
+	switch fv.Kind() { //nolint: exhaustive
+	case reflect.String:
+		return fv.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), true
+	case reflect.Float32:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, 32), true
+	case reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+func (e structEncoder) key(prefix, name string) string {
+	switch {
+	case prefix == "":
+		return name
+	case e.nesting == NestingBrackets:
+		return prefix + "[" + name + "]"
+	default:
+		return prefix + "." + name
+	}
+}
+
+func (e structEncoder) parseTag(tag string) (string, tagOptions) {
+	name, rest, _ := strings.Cut(tag, ",")
+	opts := tagOptions{arrays: e.arrays}
+
+	for opt := range strings.SplitSeq(rest, ",") {
+		switch opt {
+		case "omitempty":
+			opts.omitempty = true
+		case "comma":
+			opts.arrays = ArrayCSV
+		case "brackets":
+			opts.arrays = ArrayBrackets
+		case "repeat":
+			opts.arrays = ArrayRepeat
+		}
+	}
+
+	return name, opts
+}
+
+// indirect dereferences pointers and interfaces. It reports false for nil.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+
+		v = v.Elem()
+	}
+
+	return v, v.IsValid()
+}
@@ -0,0 +1,125 @@
+package rip
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type testStatus string
+
+type testPaging struct {
+	Page  int `url:"page,omitempty"`
+	Limit int `url:"limit,omitempty"`
+}
+
+type testFilter struct {
+	testPaging
+	Query    string       `url:"q"`
+	Status   testStatus   `url:"status,omitempty"`
+	Tags     []string     `url:"tag"`
+	IDs      []int        `url:"ids,comma"`
+	Since    *time.Time   `url:"since,omitempty"`
+	Author   *testAuthor  `url:"author,omitempty"`
+	Ignored  string       `url:"-"`
+	Statuses []testStatus `url:"statuses,omitempty"`
+	NoTag    bool
+	internal string
+}
+
+type testAuthor struct {
+	Name string `url:"name"`
+}
+
+func TestSetQueryStruct(t *testing.T) {
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	type tcase struct {
+		nesting  NestingFormat
+		arrays   ArrayFormat
+		existing url.Values
+		input    any
+		expected string
+	}
+
+	tests := map[string]tcase{
+		"omitempty and flattening": {
+			input:    testFilter{Query: "go", testPaging: testPaging{Page: 2}},
+			expected: "NoTag=false&page=2&q=go",
+		},
+		"all fields": {
+			input: &testFilter{
+				Query:    "go",
+				Status:   "open",
+				Tags:     []string{"a", "b"},
+				IDs:      []int{1, 2},
+				Since:    &since,
+				Author:   &testAuthor{Name: "ben"},
+				Ignored:  "x",
+				Statuses: []testStatus{"open", "closed"},
+				NoTag:    true,
+				internal: "x",
+			},
+			expected: "NoTag=true&author.name=ben&ids=1%2C2&q=go&since=2024-01-02T03%3A04%3A05Z" +
+				"&status=open&statuses=open&statuses=closed&tag=a&tag=b",
+		},
+		"bracket nesting and arrays": {
+			nesting: NestingBrackets,
+			arrays:  ArrayBrackets,
+			input: testFilter{
+				Tags:   []string{"a"},
+				Author: &testAuthor{Name: "ben"},
+			},
+			expected: "NoTag=false&author%5Bname%5D=ben&q=&tag%5B%5D=a",
+		},
+		"merges into existing query": {
+			existing: url.Values{"q": []string{"existing"}},
+			input:    testPaging{Page: 1},
+			expected: "page=1&q=existing",
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+
+			c, err := NewClient("http://localhost",
+				WithNestingFormat(tc.nesting),
+				WithArrayFormat(tc.arrays),
+			)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			r := c.NR()
+			r.Query = tc.existing
+			r.SetQueryStruct(tc.input)
+
+			if r.err != nil {
+				t.Fatalf("expected err to be nil, got: %v", r.err)
+			}
+
+			if got := r.Query.Encode(); got != tc.expected {
+				t.Errorf("\n expected: %v\n got:      %v", tc.expected, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestSetQueryStructInvalid(t *testing.T) {
+	c, err := NewClient("http://localhost")
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	_, err = c.NR().SetQueryStruct("nope").Execute(t.Context(), http.MethodGet, "/")
+	if !errors.Is(err, ErrUnsupportedValue) {
+		t.Errorf("expected ErrUnsupportedValue, got: %v", err)
+	}
+}
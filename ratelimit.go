@@ -0,0 +1,163 @@
+package rip
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitExceeded occurs when waiting for a token would take
+// longer than the context deadline allows.
+var ErrRateLimitExceeded = errors.New("rate limit wait exceeds context deadline")
+
+// TokenBucket is a token bucket rate limiter safe for concurrent use.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a full bucket refilling rate tokens per second
+// up to burst tokens. A rate <= 0 disables limiting.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	b := float64(max(burst, 1))
+
+	return &TokenBucket{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+		last:   time.Now(),
+	}
+}
+
+// Tokens returns the number of currently available tokens.
+// It is negative while waiters hold reservations.
+func (b *TokenBucket) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+
+	return b.tokens
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	return b.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n tokens are available or ctx is done.
+func (b *TokenBucket) WaitN(ctx context.Context, n int) error {
+	if b.rate <= 0 || math.IsInf(b.rate, 1) {
+		return ctx.Err()
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.refill(now)
+
+	wait := time.Duration(0)
+	if deficit := float64(n) - b.tokens; deficit > 0 {
+		wait = time.Duration(deficit / b.rate * float64(time.Second))
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		b.mu.Unlock()
+		return ErrRateLimitExceeded
+	}
+
+	// reserve the tokens, so concurrent waiters queue up behind us.
+	b.tokens -= float64(n)
+	b.mu.Unlock()
+
+	if err := sleep(ctx, wait); err != nil {
+		b.mu.Lock()
+		b.tokens = math.Min(b.tokens+float64(n), b.burst)
+		b.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.tokens = math.Min(b.tokens+elapsed*b.rate, b.burst)
+}
+
+// RateLimiter throttles outbound requests of a Client, optionally with
+// additional buckets per unresolved path template (e.g. /blog/:id).
+type RateLimiter struct {
+	client *TokenBucket
+	paths  map[string]*TokenBucket
+}
+
+// WithRateLimit throttles all requests of the client to rate requests
+// per second with the given burst.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(c *Client) {
+		c.rateLimiter().client = NewTokenBucket(rate, burst)
+	}
+}
+
+// WithPathRateLimit throttles requests to the path template (as passed to
+// Execute, e.g. /blog/:id) to rate requests per second with the given burst.
+// It applies in addition to WithRateLimit.
+func WithPathRateLimit(path string, rate float64, burst int) Option {
+	return func(c *Client) {
+		c.rateLimiter().paths[path] = NewTokenBucket(rate, burst)
+	}
+}
+
+// RateLimiter returns the rate limiter of the client or nil if none is set.
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.limiter
+}
+
+func (c *Client) rateLimiter() *RateLimiter {
+	if c.limiter == nil {
+		c.limiter = &RateLimiter{paths: map[string]*TokenBucket{}}
+	}
+
+	return c.limiter
+}
+
+// Wait blocks until both the client and the path bucket grant a token.
+func (l *RateLimiter) Wait(ctx context.Context, path string) error {
+	if l.client != nil {
+		if err := l.client.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if b, ok := l.paths[path]; ok {
+		return b.Wait(ctx)
+	}
+
+	return nil
+}
+
+// Tokens returns the available tokens of the client bucket.
+func (l *RateLimiter) Tokens() float64 {
+	if l.client == nil {
+		return math.Inf(1)
+	}
+
+	return l.client.Tokens()
+}
+
+// PathTokens returns the available tokens of the bucket for path.
+func (l *RateLimiter) PathTokens(path string) (float64, bool) {
+	b, ok := l.paths[path]
+	if !ok {
+		return 0, false
+	}
+
+	return b.Tokens(), true
+}
@@ -0,0 +1,92 @@
+package rip
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := NewTokenBucket(100, 2)
+
+	ctx := t.Context()
+	start := time.Now()
+
+	for range 4 {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+	}
+
+	// 2 tokens from burst, 2 refilled at 100/s
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("expected to wait for refill, waited: %v", elapsed)
+	}
+
+	if tokens := b.Tokens(); tokens > 2 {
+		t.Errorf("expected tokens to be at most burst, got: %v", tokens)
+	}
+}
+
+func TestTokenBucketContextDeadline(t *testing.T) {
+	b := NewTokenBucket(1, 1)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := b.Wait(ctx); err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+
+	err := b.Wait(ctx)
+	if !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("expected ErrRateLimitExceeded, got: %v", err)
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL,
+		WithRateLimit(1000, 10),
+		WithPathRateLimit("/blog/:id", 1, 1),
+	)
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	for i := range 2 {
+		res, err := c.NR().
+			SetParams(Params{"id": i}).
+			Execute(ctx, http.MethodGet, "/blog/:id")
+		res.Close()
+
+		switch i {
+		case 0:
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+		case 1:
+			if !errors.Is(err, ErrRateLimitExceeded) {
+				t.Fatalf("expected ErrRateLimitExceeded, got: %v", err)
+			}
+		}
+	}
+
+	if tokens, ok := c.RateLimiter().PathTokens("/blog/:id"); !ok || tokens >= 1 {
+		t.Errorf("expected path bucket to be drained, got: %v", tokens)
+	}
+
+	if tokens := c.RateLimiter().Tokens(); tokens < 8 {
+		t.Errorf("expected client bucket to hold tokens, got: %v", tokens)
+	}
+}
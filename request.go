@@ -3,13 +3,10 @@ package rip
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"strings"
 )
 
 // ErrClientMissing occurs when Request is instantiated without Client.NR()
@@ -32,10 +29,24 @@ type Request struct {
 	Path          string
 	ContentLength int64
 	Query         url.Values
-	Result        any // NOTE: can I pass struct here to unmarshal resp body to?
+	Result        any
+	ErrorResult   any
 	URL           string
 	client        *Client
 	rawRequest    *http.Request
+	template      string
+	limitKey      string
+	middleware    []Middleware
+	err           error
+	multipart     *multipartBody
+	compression   *compression
+	auth          Authenticator
+	stream        bool
+
+	uploadProgress   ProgressFunc
+	uploadLimit      float64
+	downloadProgress ProgressFunc
+	downloadLimit    float64
 }
 
 // Execute executes a given request using a method on a given path
@@ -44,36 +55,68 @@ func (r *Request) Execute(ctx context.Context, method, path string) (*Response,
 		return NewResponse(r, nil), ErrClientMissing
 	}
 
-	var err error
+	if r.err != nil {
+		return NewResponse(r, nil), r.err
+	}
 
-	r.parsePath(path, r.Params)
-	r.parseURL()
+	if err := r.parsePath(path, r.Params); err != nil {
+		return NewResponse(r, nil), err
+	}
 
-	if rd, ok := r.Body.(io.Reader); ok {
-		r.rawRequest, err = http.NewRequestWithContext(ctx, method, r.URL, rd)
-	} else {
-		r.rawRequest, err = http.NewRequestWithContext(ctx, method, r.URL, http.NoBody)
+	if err := r.parseURL(); err != nil {
+		return NewResponse(r, nil), err
 	}
+
+	body, err := r.bodyReader()
 	if err != nil {
 		return NewResponse(r, nil), err
 	}
 
+	r.rawRequest, err = http.NewRequestWithContext(ctx, method, r.URL, body)
+	if err != nil {
+		return NewResponse(r, nil), err
+	}
+
+	if err := r.setGetBody(body); err != nil {
+		return NewResponse(r, nil), err
+	}
+
 	if r.ContentLength != 0 {
 		r.rawRequest.ContentLength = r.ContentLength
 	}
 
-	r.rawRequest.Header = r.Header
+	// Request.Header is reused by subsequent requests, the layers
+	// below set headers of this request only.
+	r.rawRequest.Header = r.Header.Clone()
+	if r.rawRequest.Header == nil {
+		r.rawRequest.Header = http.Header{}
+	}
 
-	if r.Query != nil {
-		r.rawRequest.URL.RawQuery = r.Query.Encode()
+	if err := r.compress(body); err != nil {
+		return NewResponse(r, nil), err
 	}
 
-	resp, err := r.client.execute(r)
+	mergeQuery(r.rawRequest.URL, r.Query)
+
+	resp, err := r.handler()(r)
 	if err != nil {
 		return resp, err
 	}
 
-	return resp, err
+	var httpErr *HTTPError
+	if r.client.options.ErrorOnStatus && resp.IsError() {
+		httpErr = newHTTPError(resp)
+	}
+
+	if err := r.decodeResult(resp); err != nil && httpErr == nil {
+		return resp, err
+	}
+
+	if httpErr != nil {
+		return resp, httpErr
+	}
+
+	return resp, nil
 }
 
 // SetQuery to set query parameters
@@ -93,24 +136,7 @@ func (r *Request) SetContentLength(length int64) *Request {
 }
 
 func (r *Request) parseQuery(query Query) {
-	r.Query = url.Values{}
-	for k, v := range query {
-		switch v.(type) {
-		case float32:
-		case float64:
-			r.Query.Add(k, fmt.Sprintf("%.6f", v))
-		case int32:
-		case int64:
-		case int:
-			r.Query.Add(k, fmt.Sprintf("%d", v))
-		case string:
-			r.Query.Add(k, fmt.Sprintf("%s", v))
-		case bool:
-			r.Query.Add(k, fmt.Sprintf("%t", v))
-		default:
-			break
-		}
-	}
+	r.Query = r.encoder().values(query)
 }
 
 // SetParams to replace in path
@@ -120,30 +146,15 @@ func (r *Request) SetParams(params Params) *Request {
 	return r
 }
 
-func (r *Request) parsePath(path string, params Params) {
-	r.Path = path
-
-	for k, v := range params {
-		var p string
-
-		switch v.(type) {
-		case float32:
-		case float64:
-			p = fmt.Sprintf("%.6f", v)
-		case int32:
-		case int64:
-		case int:
-			p = fmt.Sprintf("%d", v)
-		case string:
-			p = fmt.Sprintf("%s", v)
-		default:
-			p = ""
-		}
+func (r *Request) parsePath(path string, params Params) error {
+	r.template = path
 
-		if p != "" {
-			r.Path = strings.Replace(r.Path, fmt.Sprintf(":%s", k), p, 1)
-		}
-	}
+	strict := r.client != nil && r.client.options.StrictPathParams
+
+	p, err := expandPath(path, params, r.encoder(), strict)
+	r.Path = p
+
+	return err
 }
 
 // SetHeader to set a single header
@@ -170,7 +181,8 @@ func (r *Request) SetHeaders(header Header) *Request {
 	return r
 }
 
-// SetBody to set a request body
+// SetBody to set a request body. Anything but an io.Reader is marshaled
+// with the codec matching the Content-Type header, defaulting to JSON.
 func (r *Request) SetBody(body any) *Request {
 	if body == nil {
 		return r
@@ -183,33 +195,145 @@ func (r *Request) SetBody(body any) *Request {
 	}
 
 	// lets otherwise assume we only get marshallable bodies
-	b := r.parseBody(body)
+	b, err := r.parseBody(body)
 	r.Body = b
+	r.err = err
 
 	return r
 }
 
-// NOTE: rn expected json only
-func (r *Request) parseBody(body any) io.Reader {
-	if body == nil {
-		return nil
+// SetFormData to set an application/x-www-form-urlencoded body from
+// url.Values, map[string][]string, map[string]string or map[string]any.
+// Values of map[string]any are formatted like query parameters.
+func (r *Request) SetFormData(data any) *Request {
+	if r.Header == nil {
+		r.Header = http.Header{}
 	}
 
-	jsonBody, err := json.Marshal(body)
+	r.Header.Set("Content-Type", contentTypeForm)
+
+	if m, ok := data.(map[string]any); ok {
+		return r.SetBody(r.encoder().values(m))
+	}
+
+	return r.SetBody(data)
+}
+
+func (r *Request) parseBody(body any) (io.Reader, error) {
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	codec, ok := r.codecs().Lookup(r.Header.Get("Content-Type"))
+	if !ok {
+		codec = JSONCodec{}
+		r.Header.Set("Content-Type", contentTypeJSON)
+	}
+
+	b, err := codec.Marshal(body)
 	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewBuffer(b), nil
+}
+
+// codecs returns the codec registry of the client or the default one.
+func (r *Request) codecs() *CodecRegistry {
+	if r.client == nil {
+		return defaultCodecs
+	}
+
+	return r.client.codecs
+}
+
+// bodyReader returns the reader to send as request body. Non-seekable
+// readers are buffered when retries are enabled, so that they can be
+// rewound between attempts.
+func (r *Request) bodyReader() (io.Reader, error) {
+	if r.multipart != nil {
+		return r.multipart.reader()
+	}
+
+	rd, ok := r.Body.(io.Reader)
+	if !ok {
+		return http.NoBody, nil
+	}
+
+	switch rd.(type) {
+	case *bytes.Buffer, io.Seeker:
+		return rd, nil
+	}
+
+	if r.client.options.Retry == nil || r.client.options.Retry.MaxAttempts < 2 {
+		return rd, nil
+	}
+
+	b, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bytes.NewReader(b)
+	r.Body = br
+
+	return br, nil
+}
+
+// setGetBody sets GetBody of the raw request, if the body can be rewound.
+func (r *Request) setGetBody(body io.Reader) error {
+	if r.multipart != nil {
+		r.Header.Set("Content-Type", r.multipart.contentType())
+
+		if r.multipart.rewindable() {
+			r.rawRequest.GetBody = r.multipart.reader
+		}
+
 		return nil
 	}
 
-	b := bytes.NewBuffer(jsonBody)
+	if s, ok := body.(io.Seeker); ok && r.rawRequest.GetBody == nil {
+		getBody, err := seekBody(body, s)
+		if err != nil {
+			return err
+		}
 
-	contentType := r.Header.Get("Content-Type")
-	if !IsJSON(contentType) {
-		r.Header.Set("Content-Type", "application/json")
+		r.rawRequest.GetBody = getBody
 	}
 
-	return b
+	return nil
 }
 
-func (r *Request) parseURL() {
-	r.URL = r.client.baseURL.String() + r.Path
+// seekBody returns a GetBody func rewinding s to its current offset.
+func seekBody(body io.Reader, s io.Seeker) (func() (io.ReadCloser, error), error) {
+	offset, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (io.ReadCloser, error) {
+		if _, err := s.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		return io.NopCloser(body), nil
+	}, nil
+}
+
+// rewindable reports whether the request body can be sent again.
+func (r *Request) rewindable() bool {
+	raw := r.rawRequest
+
+	return raw.Body == nil || raw.Body == http.NoBody || raw.GetBody != nil
+}
+
+func (r *Request) parseURL() error {
+	u, err := joinURL(r.client.baseURL, r.Path, r.client.options.URLMode)
+	if err != nil {
+		return err
+	}
+
+	r.URL = u.String()
+
+	return nil
 }
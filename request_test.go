@@ -3,6 +3,7 @@ package rip
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"testing"
@@ -27,6 +28,7 @@ func TestParseParams(t *testing.T) {
 		path     string
 		params   Params
 		expected string
+		expErr   error
 	}
 
 	tests := map[string]tcase{
@@ -39,7 +41,7 @@ func TestParseParams(t *testing.T) {
 			params: Params{
 				"test": 1.1,
 			},
-			expected: "/test/1.100000",
+			expected: "/test/1.1",
 		},
 		"test int": {
 			path: "/test/:test",
@@ -62,9 +64,9 @@ func TestParseParams(t *testing.T) {
 				"test2": 1,
 				"test3": 1.1,
 			},
-			expected: "/test/teststring/1/1.100000",
+			expected: "/test/teststring/1/1.1",
 		},
-		"test ignore object": {
+		"test object is missing": {
 			path: "/test/:test",
 			params: Params{
 				"test": map[string]any{
@@ -72,6 +74,49 @@ func TestParseParams(t *testing.T) {
 				},
 			},
 			expected: "/test/:test",
+			expErr:   ErrMissingPathParam,
+		},
+		"test missing": {
+			path:     "/test/:test",
+			expected: "/test/:test",
+			expErr:   ErrMissingPathParam,
+		},
+		"test curly braces": {
+			path: "/test/{test}/{other}",
+			params: Params{
+				"test":  "a",
+				"other": 2,
+			},
+			expected: "/test/a/2",
+		},
+		"test every occurrence": {
+			path: "/test/:test/:test",
+			params: Params{
+				"test": "a",
+			},
+			expected: "/test/a/a",
+		},
+		"test prefix names": {
+			path: "/test/:id/:id2",
+			params: Params{
+				"id":  "1",
+				"id2": "2",
+			},
+			expected: "/test/1/2",
+		},
+		"test escaping": {
+			path: "/test/:test",
+			params: Params{
+				"test": "a b/c?d",
+			},
+			expected: "/test/a%20b%2Fc%3Fd",
+		},
+		"test colon within segment": {
+			path: "/test/:id:batchGet",
+			params: Params{
+				"id": "1",
+			},
+			expected: "/test/1:batchGet",
 		},
 	}
 
@@ -80,7 +125,11 @@ func TestParseParams(t *testing.T) {
 			t.Helper()
 			r := &Request{}
 			r.SetParams(tc.params)
-			r.parsePath(tc.path, tc.params)
+			err := r.parsePath(tc.path, tc.params)
+
+			if !errors.Is(err, tc.expErr) {
+				t.Errorf("expected err: %v, got: %v", tc.expErr, err)
+			}
 
 			if r.Path != tc.expected {
 				t.Errorf("expected: %v, got: %v", tc.expected, r.Path)
@@ -113,7 +162,7 @@ func TestParseQueryParams(t *testing.T) {
 				"test3": 1.1,
 				"test4": true,
 			},
-			expected: "test1=test1&test2=1&test3=1.100000&test4=true",
+			expected: "test1=test1&test2=1&test3=1.1&test4=true",
 		},
 		"test empty query": {
 			expected: "",
@@ -333,3 +382,65 @@ func TestSetContentLength(t *testing.T) {
 		t.Run(name, fn(tc))
 	}
 }
+
+func TestSetFormData(t *testing.T) {
+	type tcase struct {
+		data     any
+		expected string
+	}
+
+	tests := map[string]tcase{
+		"url values with repeated keys": {
+			data:     url.Values{"scope": []string{"read", "write"}, "grant_type": []string{"client_credentials"}},
+			expected: "grant_type=client_credentials&scope=read&scope=write",
+		},
+		"map of strings": {
+			data:     map[string]string{"a": "1"},
+			expected: "a=1",
+		},
+		"map of any": {
+			data:     map[string]any{"a": 1, "b": true, "c": []string{"x", "y"}},
+			expected: "a=1&b=true&c=x&c=y",
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			r := &Request{Header: http.Header{}}
+			r.SetFormData(tc.data)
+
+			if ct := r.Header.Get("Content-Type"); ct != contentTypeForm {
+				t.Errorf("expected Content-Type %s, got: %s", contentTypeForm, ct)
+			}
+
+			rd, ok := r.Body.(io.Reader)
+			if !ok {
+				t.Fatalf("expected body to be set, got: %v", r.err)
+			}
+
+			b, _ := io.ReadAll(rd)
+			if string(b) != tc.expected {
+				t.Errorf("expected: %v, got: %v", tc.expected, string(b))
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestStrictPathParams(t *testing.T) {
+	c, err := NewClient("http://localhost", WithStrictPathParams())
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	_, err = c.NR().
+		SetParams(Params{"id": 1, "unused": 2}).
+		Execute(t.Context(), http.MethodGet, "/test/:id")
+	if !errors.Is(err, ErrUnusedPathParam) {
+		t.Errorf("expected ErrUnusedPathParam, got: %v", err)
+	}
+}
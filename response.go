@@ -1,22 +1,60 @@
 package rip
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// unixThreshold separates X-RateLimit-Reset values given as unix
+// timestamps from values given in seconds.
+const unixThreshold = 1_000_000_000
+
+// ErrBodyTooLarge occurs when the response body exceeds WithMaxBodySize.
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// WithMaxBodySize limits how many bytes of a response body are read
+// into memory. Zero means no limit.
+func WithMaxBodySize(n int64) Option {
+	return func(c *Client) {
+		c.options.MaxBodySize = n
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
 // Response the rip response wrapping the original request and response.
 type Response struct {
 	Request     *Request
 	rawResponse *http.Response
 	body        io.ReadCloser
+	bodyBytes   []byte
+	buffered    bool
+	err         error
+	fromCache   bool
+	encoded     *countingReader
 	Close       func() error
 }
 
+// NewResponse creates a Response, e.g. for middleware short-circuiting a request.
 func NewResponse(request *Request, rawResponse *http.Response) *Response {
 	resp := &Response{Request: request, rawResponse: rawResponse}
 	resp.Close = func() error { return nil }
+
+	if rawResponse != nil && rawResponse.Body != nil {
+		resp.body = rawResponse.Body
+		resp.Close = rawResponse.Body.Close
+	}
+
 	return resp
 }
 
@@ -57,40 +95,149 @@ func (r *Response) Header() http.Header {
 
 // String method returns the body of the server response as String.
 func (r *Response) String() string {
+	return strings.TrimSpace(string(r.Body()))
+}
+
+// Body returns Body as byte array. Read errors are returned by BodyE and Err.
+func (r *Response) Body() []byte {
+	body, _ := r.BodyE()
+
+	return body
+}
+
+// BodyE reads the body into memory on the first call and returns it on
+// every call along with the error of reading it. With WithMaxBodySize the
+// body is truncated to the limit and ErrBodyTooLarge is returned.
+func (r *Response) BodyE() ([]byte, error) {
+	if r.buffered {
+		return r.bodyBytes, r.err
+	}
+
+	r.buffered = true
+	r.bodyBytes = []byte{}
+
 	if r.body == nil {
-		return ""
+		return r.bodyBytes, nil
 	}
 	defer r.body.Close() //nolint: errcheck
 
-	body, err := io.ReadAll(r.body)
+	limit := r.maxBodySize()
+
+	rd := io.Reader(r.body)
+	if limit > 0 {
+		rd = io.LimitReader(r.body, limit+1)
+	}
+
+	b, err := io.ReadAll(rd)
+	if err == nil && limit > 0 && int64(len(b)) > limit {
+		b, err = b[:limit], fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, limit)
+	}
+
+	r.bodyBytes, r.err = b, err
+
+	return b, err
+}
+
+// Err returns the error of reading the body, nil if it was not read yet.
+func (r *Response) Err() error {
+	return r.err
+}
+
+// Decode decodes the body into v using the codec matching the response
+// Content-Type, falling back to the Accept header of the request.
+func (r *Response) Decode(v any) error {
+	ct := r.Header().Get("Content-Type")
+	if ct == "" && r.Request != nil {
+		ct = r.Request.Header.Get("Accept")
+	}
+
+	codecs := defaultCodecs
+	if r.Request != nil {
+		codecs = r.Request.codecs()
+	}
+
+	body, err := r.BodyE()
 	if err != nil {
-		return ""
+		return err
 	}
 
-	return strings.TrimSpace(string(body))
+	return codecs.Unmarshal(ct, body, v)
 }
 
-// Body returns Body as byte array
-func (r *Response) Body() []byte {
+// peek reads up to n bytes of the body without consuming them.
+func (r *Response) peek(n int) []byte {
+	if r.buffered {
+		return r.bodyBytes[:min(len(r.bodyBytes), n)]
+	}
+
 	if r.body == nil {
 		return []byte{}
 	}
-	defer r.body.Close() //nolint: errcheck
 
-	body, err := io.ReadAll(r.body)
-	if err != nil {
-		return []byte{}
+	b, _ := io.ReadAll(io.LimitReader(r.body, int64(n)))
+
+	r.body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(b), r.body),
+		Closer: r.body,
 	}
 
-	return body
+	return b
+}
+
+func (r *Response) maxBodySize() int64 {
+	if r.Request == nil || r.Request.client == nil {
+		return 0
+	}
+
+	return r.Request.client.options.MaxBodySize
+}
+
+// Links returns the RFC 8288 Link header as map of relation to URL.
+// Relative URLs are resolved against the request URL.
+func (r *Response) Links() map[string]string {
+	links := map[string]string{}
+
+	var base *url.URL
+	if r.Request != nil && r.Request.rawRequest != nil {
+		base = r.Request.rawRequest.URL
+	}
+
+	for _, h := range r.Header().Values("Link") {
+		for _, l := range parseLinks(h) {
+			ref, err := url.Parse(l.url)
+			if err != nil {
+				continue
+			}
+
+			if base != nil {
+				ref = base.ResolveReference(ref)
+			}
+
+			for rel := range strings.FieldsSeq(strings.ToLower(l.rel)) {
+				if _, ok := links[rel]; !ok {
+					links[rel] = ref.String()
+				}
+			}
+		}
+	}
+
+	return links
 }
 
-// RawBody returns raw response body. be sure to close
+// RawBody returns the response body. Before the body was read by Body,
+// String, Decode or BodyE, it streams from the connection, be sure to close.
+// Afterwards it returns a new reader of the buffered body on every call.
 func (r *Response) RawBody() io.ReadCloser {
-	if r.rawResponse == nil {
-		return nil
+	if r.buffered {
+		return io.NopCloser(bytes.NewReader(r.bodyBytes))
 	}
-	return r.rawResponse.Body
+
+	return r.body
+}
+
+// FromCache reports whether the response was served from the cache.
+func (r *Response) FromCache() bool {
+	return r.fromCache
 }
 
 // IsSuccess returns true if 199 < StatusCode < 300
@@ -102,3 +249,36 @@ func (r *Response) IsSuccess() bool {
 func (r *Response) IsError() bool {
 	return r.StatusCode() > 399
 }
+
+// RetryAfter returns how long the server asked to wait before retrying.
+// It reads the Retry-After header (seconds or HTTP-date) and falls back
+// to X-RateLimit-Reset (unix timestamp or seconds). The boolean reports
+// whether any of these headers was present and valid.
+func (r *Response) RetryAfter() (time.Duration, bool) {
+	h := r.Header()
+
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if s, err := strconv.ParseInt(v, 10, 64); err == nil && s >= 0 {
+			return time.Duration(s) * time.Second, true
+		}
+
+		if t, err := http.ParseTime(v); err == nil {
+			return max(time.Until(t), 0), true
+		}
+	}
+
+	if v := strings.TrimSpace(h.Get("X-RateLimit-Reset")); v != "" {
+		s, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || s < 0 {
+			return 0, false
+		}
+
+		if s >= unixThreshold {
+			return max(time.Until(time.Unix(s, 0)), 0), true
+		}
+
+		return time.Duration(s) * time.Second, true
+	}
+
+	return 0, false
+}
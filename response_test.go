@@ -0,0 +1,186 @@
+package rip
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseRetryAfter(t *testing.T) {
+	type tcase struct {
+		header http.Header
+		expOk  bool
+		expMin time.Duration
+		expMax time.Duration
+	}
+
+	tests := map[string]tcase{
+		"no header": {
+			header: http.Header{},
+		},
+		"retry-after seconds": {
+			header: http.Header{"Retry-After": []string{"120"}},
+			expOk:  true,
+			expMin: 120 * time.Second,
+			expMax: 120 * time.Second,
+		},
+		"retry-after http-date": {
+			header: http.Header{"Retry-After": []string{
+				time.Now().Add(time.Minute).UTC().Format(http.TimeFormat),
+			}},
+			expOk:  true,
+			expMin: 58 * time.Second,
+			expMax: time.Minute,
+		},
+		"retry-after date in the past": {
+			header: http.Header{"Retry-After": []string{
+				time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat),
+			}},
+			expOk: true,
+		},
+		"retry-after invalid": {
+			header: http.Header{"Retry-After": []string{"soon"}},
+		},
+		"x-ratelimit-reset seconds": {
+			header: http.Header{"X-Ratelimit-Reset": []string{"30"}},
+			expOk:  true,
+			expMin: 30 * time.Second,
+			expMax: 30 * time.Second,
+		},
+		"x-ratelimit-reset unix timestamp": {
+			header: http.Header{"X-Ratelimit-Reset": []string{
+				strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10),
+			}},
+			expOk:  true,
+			expMin: 58 * time.Second,
+			expMax: time.Minute,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			res := NewResponse(nil, &http.Response{Header: tc.header})
+
+			got, ok := res.RetryAfter()
+			if ok != tc.expOk {
+				t.Fatalf("expected ok to be %t, got: %t", tc.expOk, ok)
+			}
+
+			if got < tc.expMin || got > tc.expMax {
+				t.Errorf("expected %v <= wait <= %v, got: %v", tc.expMin, tc.expMax, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, io.ErrUnexpectedEOF }
+
+func TestResponseBody(t *testing.T) {
+	type tcase struct {
+		body    io.Reader
+		maxSize int64
+		expBody string
+		expErr  error
+	}
+
+	tests := map[string]tcase{
+		"body can be read repeatedly": {
+			body:    strings.NewReader(" test "),
+			expBody: " test ",
+		},
+		"body exceeding the limit is truncated": {
+			body:    strings.NewReader("testtest"),
+			maxSize: 4,
+			expBody: "test",
+			expErr:  ErrBodyTooLarge,
+		},
+		"body within the limit": {
+			body:    strings.NewReader("test"),
+			maxSize: 4,
+			expBody: "test",
+		},
+		"read error is surfaced": {
+			body:    errReader{},
+			expBody: "",
+			expErr:  io.ErrUnexpectedEOF,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			c, err := NewClient("http://localhost", WithMaxBodySize(tc.maxSize))
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			res := NewResponse(c.NR(), &http.Response{Body: io.NopCloser(tc.body)})
+
+			if got := string(res.Body()); got != tc.expBody {
+				t.Errorf("expected Body: %q, got: %q", tc.expBody, got)
+			}
+
+			if got := res.String(); got != strings.TrimSpace(tc.expBody) {
+				t.Errorf("expected String: %q, got: %q", strings.TrimSpace(tc.expBody), got)
+			}
+
+			b, err := res.BodyE()
+			if string(b) != tc.expBody || !errors.Is(err, tc.expErr) {
+				t.Errorf("expected BodyE: %q, %v, got: %q, %v", tc.expBody, tc.expErr, b, err)
+			}
+
+			if !errors.Is(res.Err(), tc.expErr) {
+				t.Errorf("expected Err: %v, got: %v", tc.expErr, res.Err())
+			}
+
+			raw, _ := io.ReadAll(res.RawBody())
+			if string(raw) != tc.expBody {
+				t.Errorf("expected RawBody: %q, got: %q", tc.expBody, raw)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestResponseDecodeAfterString(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.Write([]byte(`{"name":"test"}`)) //nolint: errcheck
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	res, err := c.NR().Execute(t.Context(), http.MethodGet, "/")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if res.String() != `{"name":"test"}` {
+		t.Errorf("expected body, got: %q", res.String())
+	}
+
+	var v struct{ Name string }
+	if err := res.Decode(&v); err != nil || v.Name != "test" {
+		t.Errorf("expected body to decode after String, got: %+v, %v", v, err)
+	}
+}
@@ -0,0 +1,66 @@
+package rip
+
+import (
+	"context"
+	"net/http"
+)
+
+// SetResult sets a pointer to decode a successful (2xx) response body into.
+// The body is decoded with the codec matching the response Content-Type.
+func (r *Request) SetResult(v any) *Request {
+	r.Result = v
+
+	return r
+}
+
+// SetErrorResult sets a pointer to decode an error (4xx, 5xx) response body into.
+// The body is decoded with the codec matching the response Content-Type.
+func (r *Request) SetErrorResult(v any) *Request {
+	r.ErrorResult = v
+
+	return r
+}
+
+// Do executes req and decodes a successful response body into T.
+func Do[T any](ctx context.Context, req *Request, method, path string) (T, *Response, error) {
+	var result T
+
+	res, err := req.SetResult(&result).Execute(ctx, method, path)
+
+	return result, res, err
+}
+
+// decodeResult decodes the response body into Result or ErrorResult.
+// Responses without a body leave them untouched.
+func (r *Request) decodeResult(res *Response) error {
+	var target any
+
+	switch {
+	case res.IsSuccess():
+		target = r.Result
+	case res.IsError():
+		target = r.ErrorResult
+	}
+
+	if target == nil || !res.hasBody() {
+		return nil
+	}
+
+	body, err := res.BodyE()
+	if err != nil || len(body) == 0 {
+		return err
+	}
+
+	return res.Decode(target)
+}
+
+// hasBody reports whether the response may carry a body, see RFC 9110 6.4.1.
+func (r *Response) hasBody() bool {
+	if r.Request != nil && r.Request.rawRequest != nil && r.Request.rawRequest.Method == http.MethodHead {
+		return false
+	}
+
+	code := r.StatusCode()
+
+	return code != http.StatusNoContent && code != http.StatusNotModified
+}
@@ -0,0 +1,156 @@
+package rip
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testResult struct {
+	Data struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	} `json:"data"`
+}
+
+type testErrorResult struct {
+	Message string `json:"message"`
+}
+
+func setupResultServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+
+		if r.URL.Path == "/ok" {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, fixture("response.json"))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"not found"}`)
+	}))
+}
+
+func TestDo(t *testing.T) {
+	server := setupResultServer()
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	result, res, err := Do[testResult](t.Context(), c.NR(), http.MethodGet, "/ok")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if result.Data.Name != "test" || result.Data.Age != 21 {
+		t.Errorf("expected decoded result, got: %+v", result)
+	}
+}
+
+func TestSetErrorResult(t *testing.T) {
+	server := setupResultServer()
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	result := &testResult{}
+	errResult := &testErrorResult{}
+
+	res, err := c.NR().
+		SetResult(result).
+		SetErrorResult(errResult).
+		Execute(t.Context(), http.MethodGet, "/fails")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if errResult.Message != "not found" {
+		t.Errorf("expected error result to be decoded, got: %+v", errResult)
+	}
+
+	if result.Data.Name != "" {
+		t.Errorf("expected result to be untouched, got: %+v", result)
+	}
+}
+
+func TestDoWithoutBody(t *testing.T) {
+	type tcase struct {
+		method string
+		status int
+		accept string
+	}
+
+	tests := map[string]tcase{
+		"no content": {
+			method: http.MethodDelete,
+			status: http.StatusNoContent,
+		},
+		"no content with accept": {
+			method: http.MethodDelete,
+			status: http.StatusNoContent,
+			accept: contentTypeJSON,
+		},
+		"empty body": {
+			method: http.MethodPost,
+			status: http.StatusOK,
+			accept: contentTypeJSON,
+		},
+		"head": {
+			method: http.MethodHead,
+			status: http.StatusOK,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tc.method == http.MethodHead {
+					w.Header().Set("Content-Type", contentTypeJSON)
+				}
+
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			c, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			req := c.NR()
+			if tc.accept != "" {
+				req.SetHeader("Accept", tc.accept)
+			}
+
+			result, res, err := Do[testResult](t.Context(), req, tc.method, "/blog/1")
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+			defer res.Close()
+
+			if res.StatusCode() != tc.status {
+				t.Errorf("expected StatusCode %d, got: %d", tc.status, res.StatusCode())
+			}
+
+			if result.Data.Name != "" {
+				t.Errorf("expected result to be untouched, got: %+v", result)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
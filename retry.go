@@ -0,0 +1,156 @@
+package rip
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 10 * time.Second
+	defaultRetryMultiplier     = 2.0
+)
+
+// RetryFunc decides whether an attempt should be retried.
+type RetryFunc func(res *Response, err error) bool
+
+// RetryPolicy configures how failed requests are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts.
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after each attempt.
+	Multiplier float64
+	// Jitter randomly shortens each backoff by up to this fraction (0..1).
+	Jitter float64
+	// PerAttemptTimeout bounds a single attempt. Zero means no bound.
+	PerAttemptTimeout time.Duration
+	// MaxElapsed is the total budget. No attempt is started that would
+	// exceed it and an attempt still running when it is spent is canceled.
+	// Zero means no budget.
+	MaxElapsed time.Duration
+	// ShouldRetry decides whether an attempt is retried.
+	// Defaults to DefaultShouldRetry.
+	ShouldRetry RetryFunc
+}
+
+// WithRetry enables retries using the given policy.
+// Zero values in policy are replaced by sensible defaults.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		p := policy.withDefaults()
+		c.options.Retry = &p
+	}
+}
+
+// WithRetryAfter reissues requests answered with 429 or 503 once the
+// duration given by the Retry-After or X-RateLimit-Reset header elapsed.
+// Waits longer than maxWait (if > 0) or beyond the context deadline are
+// not honored and the response is returned as is. Reissues are bounded by
+// the retry policy's MaxAttempts, or happen once without a retry policy.
+func WithRetryAfter(maxWait time.Duration) Option {
+	return func(c *Client) {
+		c.options.RetryAfter = true
+		c.options.MaxRetryAfter = maxWait
+	}
+}
+
+// DefaultShouldRetry retries on transport errors, 429 and 5xx responses
+// except 501 Not Implemented. Requests rejected by an open circuit
+// breaker are not retried.
+func DefaultShouldRetry(res *Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, ErrCircuitOpen)
+	}
+
+	if res == nil {
+		return false
+	}
+
+	code := res.StatusCode()
+
+	return code == http.StatusTooManyRequests ||
+		(code >= http.StatusInternalServerError && code != http.StatusNotImplemented)
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = defaultRetryMaxAttempts
+	}
+
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultRetryInitialBackoff
+	}
+
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+
+	if p.Multiplier < 1 {
+		p.Multiplier = defaultRetryMultiplier
+	}
+
+	p.Jitter = math.Max(0, math.Min(p.Jitter, 1))
+
+	if p.ShouldRetry == nil {
+		p.ShouldRetry = DefaultShouldRetry
+	}
+
+	return p
+}
+
+// backoff returns the wait after the given (1-based) attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	d = math.Min(d, float64(p.MaxBackoff))
+
+	if p.Jitter > 0 {
+		d -= d * p.Jitter * rand.Float64()
+	}
+
+	return time.Duration(d)
+}
+
+func retryAfterStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// discard drains and closes the response body so the connection
+// can be reused before the next attempt.
+func (r *Response) discard() {
+	if r == nil {
+		return
+	}
+
+	if r.body != nil && !r.buffered {
+		_, _ = io.Copy(io.Discard, r.body)
+	}
+
+	_ = r.Close()
+}
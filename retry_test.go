@@ -0,0 +1,343 @@
+package rip
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	type tcase struct {
+		policy        RetryPolicy
+		failures      int32
+		body          io.Reader
+		expAttempts   int32
+		expStatusCode int
+	}
+
+	tests := map[string]tcase{
+		"no retry without policy": {
+			failures:      1,
+			expAttempts:   1,
+			expStatusCode: http.StatusServiceUnavailable,
+		},
+		"retry until success": {
+			policy:        RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			failures:      2,
+			expAttempts:   3,
+			expStatusCode: http.StatusOK,
+		},
+		"retry exhausts attempts": {
+			policy:        RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+			failures:      5,
+			expAttempts:   2,
+			expStatusCode: http.StatusServiceUnavailable,
+		},
+		"retry resends seekable body": {
+			policy:        RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			failures:      2,
+			body:          strings.NewReader("payload"),
+			expAttempts:   3,
+			expStatusCode: http.StatusOK,
+		},
+		"retry resends one-shot body": {
+			policy:        RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			failures:      2,
+			body:          io.MultiReader(strings.NewReader("pay"), strings.NewReader("load")),
+			expAttempts:   3,
+			expStatusCode: http.StatusOK,
+		},
+		"custom predicate": {
+			policy: RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				ShouldRetry:    func(*Response, error) bool { return false },
+			},
+			failures:      2,
+			expAttempts:   1,
+			expStatusCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := attempts.Add(1)
+
+				if tc.body != nil {
+					b, _ := io.ReadAll(r.Body)
+					if string(b) != "payload" {
+						t.Errorf("attempt %d: expected body payload, got: %q", n, string(b))
+					}
+				}
+
+				if n <= tc.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			options := []Option{}
+			if tc.policy.MaxAttempts != 0 {
+				options = append(options, WithRetry(tc.policy))
+			}
+
+			c, err := NewClient(server.URL, options...)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			req := c.NR()
+			if tc.body != nil {
+				req.SetBody(tc.body)
+			}
+
+			res, err := req.Execute(t.Context(), http.MethodPost, "/")
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+			defer res.Close()
+
+			if res.StatusCode() != tc.expStatusCode {
+				t.Errorf("expected StatusCode %d, got: %d", tc.expStatusCode, res.StatusCode())
+			}
+
+			if got := attempts.Load(); got != tc.expAttempts {
+				t.Errorf("expected %d attempts, got: %d", tc.expAttempts, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestRetryContextCancel(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithRetry(RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+	}))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	res, err := c.NR().Execute(ctx, http.MethodGet, "/")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context deadline exceeded, got: %v", err)
+	}
+	defer res.Close()
+
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("expected 1 attempt, got: %d", got)
+	}
+}
+
+func TestRetryPerAttemptTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithRetry(RetryPolicy{
+		MaxAttempts:       2,
+		InitialBackoff:    time.Millisecond,
+		PerAttemptTimeout: 50 * time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	res, err := c.NR().Execute(t.Context(), http.MethodGet, "/")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if res.StatusCode() != http.StatusOK {
+		t.Errorf("expected StatusCode 200, got: %d", res.StatusCode())
+	}
+}
+
+func TestRetryMaxElapsed(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, WithRetry(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxElapsed:     100 * time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	start := time.Now()
+
+	_, err = c.NR().Execute(t.Context(), http.MethodGet, "/")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context deadline exceeded, got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected attempt to be canceled once the budget is spent, took: %v", elapsed)
+	}
+
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("expected 2 attempts, got: %d", got)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}.withDefaults()
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+	}
+
+	for i, exp := range expected {
+		if got := p.backoff(i + 1); got != exp {
+			t.Errorf("attempt %d: expected backoff %v, got: %v", i+1, exp, got)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 1; i < 5; i++ {
+		got := p.backoff(i)
+		if got > expected[i-1] || got < expected[i-1]/2 {
+			t.Errorf("attempt %d: jittered backoff %v out of range", i, got)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	type tcase struct {
+		header      string
+		value       string
+		timeout     time.Duration
+		maxWait     time.Duration
+		expAttempts int32
+	}
+
+	tests := map[string]tcase{
+		"retry-after seconds": {
+			header:      "Retry-After",
+			value:       "0",
+			expAttempts: 2,
+		},
+		"retry-after http-date": {
+			header:      "Retry-After",
+			value:       time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat),
+			expAttempts: 2,
+		},
+		"x-ratelimit-reset": {
+			header:      "X-RateLimit-Reset",
+			value:       "0",
+			expAttempts: 2,
+		},
+		"exceeds max wait": {
+			header:      "Retry-After",
+			value:       "60",
+			maxWait:     time.Second,
+			expAttempts: 1,
+		},
+		"exceeds context deadline": {
+			header:      "Retry-After",
+			value:       "60",
+			timeout:     time.Second,
+			expAttempts: 1,
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if attempts.Add(1) == 1 {
+					w.Header().Set(tc.header, tc.value)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			c, err := NewClient(server.URL, WithRetryAfter(tc.maxWait))
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			ctx := t.Context()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			res, err := c.NR().Execute(ctx, http.MethodGet, "/")
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+			defer res.Close()
+
+			if got := attempts.Load(); got != tc.expAttempts {
+				t.Errorf("expected %d attempts, got: %d", tc.expAttempts, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
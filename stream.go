@@ -0,0 +1,256 @@
+package rip
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	contentTypeEventStream = "text/event-stream"
+	contentTypeNDJSON      = "application/x-ndjson"
+	defaultSSERetry        = 3 * time.Second
+)
+
+// Event is a server-sent event.
+type Event struct {
+	// ID is the last event ID sent by the server.
+	ID string
+	// Event is the event type, "message" if not set by the server.
+	Event string
+	Data  string
+	// Retry is the reconnection time sent along with the event, if any.
+	Retry time.Duration
+}
+
+// Lines iterates over the lines of the body without the line endings.
+// The response is closed when the iteration stops.
+func (r *Response) Lines() iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		defer r.Close() //nolint: errcheck
+
+		body := r.RawBody()
+		if body == nil {
+			return
+		}
+
+		br := bufio.NewReader(body)
+
+		for {
+			line, err := readLine(br)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if !yield(line, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// NDJSON iterates over a body of newline delimited JSON, decoding each
+// line into T. Empty lines are skipped. The response is closed when the
+// iteration stops.
+func NDJSON[T any](r *Response) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for line, err := range r.Lines() {
+			var v T
+			if err == nil {
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+
+				err = json.Unmarshal([]byte(line), &v)
+			}
+
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Events iterates over the server-sent events of the body. The response
+// is closed when the iteration stops.
+func (r *Response) Events() iter.Seq2[Event, error] {
+	return r.events(&eventStream{})
+}
+
+// eventStream holds the last event ID and reconnection time of an event
+// stream, which apply whether or not an event is dispatched.
+type eventStream struct {
+	lastID string
+	retry  time.Duration
+}
+
+// events parses the event stream, updating s with its id and retry fields.
+func (r *Response) events(s *eventStream) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		var (
+			data  strings.Builder
+			id    = s.lastID
+			event string
+			retry time.Duration
+		)
+
+		for line, err := range r.Lines() {
+			if err != nil {
+				yield(Event{ID: s.lastID}, err)
+				return
+			}
+
+			if line == "" {
+				s.lastID = id
+
+				if data.Len() > 0 {
+					ev := Event{
+						ID:    id,
+						Event: cmp.Or(event, "message"),
+						Data:  strings.TrimSuffix(data.String(), "\n"),
+						Retry: retry,
+					}
+
+					if !yield(ev, nil) {
+						return
+					}
+
+					retry = 0
+				}
+
+				data.Reset()
+				event = ""
+
+				continue
+			}
+
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+
+			switch field {
+			case "event":
+				event = value
+			case "data":
+				data.WriteString(value + "\n")
+			case "id":
+				if !strings.ContainsRune(value, 0) {
+					id = value
+				}
+			case "retry":
+				if ms, err := strconv.ParseUint(value, 10, 64); err == nil && ms > 0 {
+					retry = time.Duration(ms) * time.Millisecond
+					s.retry = retry
+				}
+			}
+		}
+	}
+}
+
+// Events subscribes to the server-sent events of path. If the stream ends
+// or fails, the request is executed again after the retry delay sent by the
+// server (3s by default), sending the ID of the last event as Last-Event-ID.
+// Errors are yielded; the iteration stops on a non-2xx response, on 204
+// No Content, when ctx is done or the loop body breaks.
+func (r *Request) Events(ctx context.Context, path string) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		if r.Header == nil {
+			r.Header = http.Header{}
+		}
+
+		r.Header.Set("Accept", contentTypeEventStream)
+		r.Header.Set("Cache-Control", "no-cache")
+
+		stream := &eventStream{retry: defaultSSERetry}
+
+		for {
+			if stream.lastID != "" {
+				r.Header.Set("Last-Event-ID", stream.lastID)
+			}
+
+			res, err := r.Execute(ctx, http.MethodGet, path)
+
+			switch {
+			case err != nil:
+				if res != nil {
+					res.Close() //nolint: errcheck
+				}
+
+				if ctx.Err() != nil {
+					yield(Event{}, ctx.Err())
+					return
+				}
+
+				var httpErr *HTTPError
+				if !yield(Event{}, err) || errors.As(err, &httpErr) {
+					return
+				}
+			case res.StatusCode() == http.StatusNoContent:
+				res.Close() //nolint: errcheck
+				return
+			case !res.IsSuccess():
+				yield(Event{}, newHTTPError(res))
+				res.Close() //nolint: errcheck
+
+				return
+			default:
+				for ev, err := range res.events(stream) {
+					if err != nil && ctx.Err() != nil {
+						yield(ev, ctx.Err())
+						return
+					}
+
+					if !yield(ev, err) {
+						return
+					}
+				}
+			}
+
+			if err := sleep(ctx, stream.retry); err != nil {
+				yield(Event{}, err)
+				return
+			}
+		}
+	}
+}
+
+// SetStream marks the response to be read as it arrives, e.g. with Lines
+// or NDJSON, so that it is not buffered by the cache or request coalescing.
+// Requests accepting text/event-stream or application/x-ndjson are
+// streamed without it.
+func (r *Request) SetStream(stream bool) *Request {
+	r.stream = stream
+
+	return r
+}
+
+// streaming reports whether the caller reads the response body as it
+// arrives, so that it must not be buffered by the cache or coalescing.
+func (r *Request) streaming() bool {
+	accept := r.rawRequest.Header.Get("Accept")
+
+	return r.stream ||
+		strings.Contains(accept, contentTypeEventStream) ||
+		strings.Contains(accept, contentTypeNDJSON)
+}
+
+// readLine reads a line terminated by \n or \r\n. The last line of the
+// reader may be unterminated.
+func readLine(br *bufio.Reader) (string, error) {
+	line, err := br.ReadString('\n')
+	if errors.Is(err, io.EOF) && line != "" {
+		err = nil
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+
+	return line, err
+}
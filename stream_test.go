@@ -0,0 +1,303 @@
+package rip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLines(t *testing.T) {
+	type tcase struct {
+		body     string
+		expected []string
+	}
+
+	tests := map[string]tcase{
+		"lf": {
+			body:     "a\nb\n",
+			expected: []string{"a", "b"},
+		},
+		"crlf and unterminated last line": {
+			body:     "a\r\n\r\nb",
+			expected: []string{"a", "", "b"},
+		},
+		"empty": {
+			body: "",
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			res := NewResponse(nil, &http.Response{Body: io.NopCloser(strings.NewReader(tc.body))})
+
+			var got []string
+
+			for line, err := range res.Lines() {
+				if err != nil {
+					t.Fatalf("expected err to be nil, got: %v", err)
+				}
+
+				got = append(got, line)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tc.expected) {
+				t.Errorf("expected: %q, got: %q", tc.expected, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	type record struct {
+		ID int `json:"id"`
+	}
+
+	body := "{\"id\":1}\n\n{\"id\":2}\nnot json\n{\"id\":3}\n"
+	res := NewResponse(nil, &http.Response{Body: io.NopCloser(strings.NewReader(body))})
+
+	var (
+		ids    []int
+		gotErr error
+	)
+
+	for rec, err := range NDJSON[record](res) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+
+		ids = append(ids, rec.ID)
+	}
+
+	if fmt.Sprint(ids) != "[1 2]" {
+		t.Errorf("expected records up to the invalid line, got: %v", ids)
+	}
+
+	if gotErr == nil {
+		t.Error("expected decoding error")
+	}
+}
+
+func TestStreamingRequests(t *testing.T) {
+	type tcase struct {
+		request func(c *Client) *Request
+	}
+
+	tests := map[string]tcase{
+		"accepts ndjson": {
+			request: func(c *Client) *Request {
+				return c.NR().SetHeader("Accept", contentTypeNDJSON)
+			},
+		},
+		"set stream": {
+			request: func(c *Client) *Request {
+				return c.NR().SetStream(true)
+			},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentTypeNDJSON)
+		fmt.Fprint(w, "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n")
+	}))
+	defer server.Close()
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			c, err := NewClient(server.URL, WithCoalescing(), WithMaxBodySize(10))
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			res, err := tc.request(c).Execute(t.Context(), http.MethodGet, "/export")
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+
+			var ids []int
+
+			for rec, err := range NDJSON[struct{ ID int }](res) {
+				if err != nil {
+					t.Fatalf("expected err to be nil, got: %v", err)
+				}
+
+				ids = append(ids, rec.ID)
+			}
+
+			if fmt.Sprint(ids) != "[1 2 3]" {
+				t.Errorf("expected all records to be streamed, got: %v", ids)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestResponseEvents(t *testing.T) {
+	body := ": comment\n" +
+		"data: first\n\n" +
+		"event: update\nid: 1\nretry: 100\ndata: a\ndata:b\n\n" +
+		"id: 2\n\n" +
+		"data: last"
+
+	res := NewResponse(nil, &http.Response{Body: io.NopCloser(strings.NewReader(body))})
+
+	var got []Event
+
+	for ev, err := range res.Events() {
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		got = append(got, ev)
+	}
+
+	expected := []Event{
+		{Event: "message", Data: "first"},
+		{ID: "1", Event: "update", Data: "a\nb", Retry: 100 * time.Millisecond},
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, got)
+	}
+}
+
+func TestRequestEvents(t *testing.T) {
+	var connects atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentTypeEventStream)
+
+		switch connects.Add(1) {
+		case 1:
+			fmt.Fprint(w, "retry: 10\nid: 1\ndata: one\n\n")
+		case 2:
+			fmt.Fprintf(w, "id: 2\ndata: %s\n\n", r.Header.Get("Last-Event-ID"))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	var got []string
+
+	for ev, err := range c.NR().Events(t.Context(), "/events") {
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %v", err)
+		}
+
+		got = append(got, ev.ID+":"+ev.Data)
+	}
+
+	if fmt.Sprint(got) != "[1:one 2:1]" {
+		t.Errorf("expected reconnect with Last-Event-ID, got: %v", got)
+	}
+
+	if connects.Load() != 3 {
+		t.Errorf("expected 3 connections, got: %d", connects.Load())
+	}
+}
+
+func TestRequestEventsReconnect(t *testing.T) {
+	type tcase struct {
+		bodies   []string
+		expected string
+	}
+
+	tests := map[string]tcase{
+		"id without data": {
+			bodies:   []string{"retry: 10\nid: 7\n\n", "data: {id}\n\n"},
+			expected: "[7:7]",
+		},
+		"retry without event": {
+			bodies:   []string{"id: 1\ndata: one\n\nretry: 10\n", "data: {id}\n\n"},
+			expected: "[1:one 1:1]",
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+
+			var connects atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(connects.Add(1))
+				if n > len(tc.bodies) {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+
+				w.Header().Set("Content-Type", contentTypeEventStream)
+				fmt.Fprint(w, strings.ReplaceAll(tc.bodies[n-1], "{id}", r.Header.Get("Last-Event-ID")))
+			}))
+			defer server.Close()
+
+			c, err := NewClient(server.URL)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+			defer cancel()
+
+			var got []string
+
+			for ev, err := range c.NR().Events(ctx, "/events") {
+				if err != nil {
+					t.Fatalf("expected err to be nil, got: %v", err)
+				}
+
+				got = append(got, ev.ID+":"+ev.Data)
+			}
+
+			if fmt.Sprint(got) != tc.expected {
+				t.Errorf("expected: %v, got: %v", tc.expected, got)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestRequestEventsStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	var httpErr *HTTPError
+
+	for _, err := range c.NR().Events(t.Context(), "/events") {
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected HTTPError 401, got: %v", err)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package rip
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// maxTransferChunk bounds the burst of bandwidth limits, and so the bytes
+// read at once by a throttled transfer, so that the bandwidth is spread
+// evenly.
+const maxTransferChunk = 32 << 10
+
+// WithBandwidthLimit limits the bytes per second uploaded and downloaded
+// by all requests of the client combined. Zero means no limit.
+func WithBandwidthLimit(upload, download float64) Option {
+	return func(c *Client) {
+		c.uploadBudget = bandwidthBucket(upload)
+		c.downloadBudget = bandwidthBucket(download)
+	}
+}
+
+// SetUploadProgress reports the bytes of the body sent so far and the total
+// from the content length, -1 if unknown. It restarts with every retry.
+func (r *Request) SetUploadProgress(fn ProgressFunc) *Request {
+	r.uploadProgress = fn
+
+	return r
+}
+
+// SetUploadLimit limits the bytes per second sent for the body.
+func (r *Request) SetUploadLimit(bytesPerSec float64) *Request {
+	r.uploadLimit = bytesPerSec
+
+	return r
+}
+
+// SetDownloadProgress reports the bytes of the response body read so far
+// and the total from the content length, -1 if unknown.
+func (r *Request) SetDownloadProgress(fn ProgressFunc) *Request {
+	r.downloadProgress = fn
+
+	return r
+}
+
+// SetDownloadLimit limits the bytes per second read from the response body.
+func (r *Request) SetDownloadLimit(bytesPerSec float64) *Request {
+	r.downloadLimit = bytesPerSec
+
+	return r
+}
+
+// wrapUpload wraps the body of raw with the upload progress and limits.
+func (r *Request) wrapUpload(ctx context.Context, raw *http.Request) {
+	if raw.Body == nil || raw.Body == http.NoBody {
+		return
+	}
+
+	buckets := budgets(bandwidthBucket(r.uploadLimit), r.client.uploadBudget)
+	if r.uploadProgress == nil && len(buckets) == 0 {
+		return
+	}
+
+	raw.Body = newTransferReader(ctx, raw.Body, buckets, r.uploadProgress, raw.ContentLength)
+}
+
+// wrapDownload wraps the response body with the download progress and limits.
+func (r *Request) wrapDownload(ctx context.Context, resp *http.Response) io.ReadCloser {
+	buckets := budgets(bandwidthBucket(r.downloadLimit), r.client.downloadBudget)
+	if resp.Body == nil || (r.downloadProgress == nil && len(buckets) == 0) {
+		return resp.Body
+	}
+
+	return newTransferReader(ctx, resp.Body, buckets, r.downloadProgress, resp.ContentLength)
+}
+
+// transferReader reports progress and throttles reads by token buckets
+// holding one token per byte.
+type transferReader struct {
+	io.ReadCloser
+	ctx      context.Context //nolint: containedctx
+	buckets  []*TokenBucket
+	chunk    int
+	progress ProgressFunc
+	n        int64
+	total    int64
+}
+
+// newTransferReader creates a transferReader reading at most the smallest
+// burst of buckets at once, so that a read never waits for more tokens
+// than a bucket holds.
+func newTransferReader(
+	ctx context.Context,
+	rc io.ReadCloser,
+	buckets []*TokenBucket,
+	progress ProgressFunc,
+	length int64,
+) *transferReader {
+	t := &transferReader{
+		ReadCloser: rc,
+		ctx:        ctx,
+		buckets:    buckets,
+		progress:   progress,
+		total:      contentLength(length),
+	}
+
+	for _, b := range buckets {
+		if t.chunk == 0 || int(b.burst) < t.chunk {
+			t.chunk = int(b.burst)
+		}
+	}
+
+	return t
+}
+
+func (t *transferReader) Read(p []byte) (int, error) {
+	if t.chunk > 0 && len(p) > t.chunk {
+		p = p[:t.chunk]
+	}
+
+	n, err := t.ReadCloser.Read(p)
+	if n == 0 {
+		return n, err
+	}
+
+	for _, b := range t.buckets {
+		if wErr := b.WaitN(t.ctx, n); wErr != nil {
+			return n, wErr
+		}
+	}
+
+	t.n += int64(n)
+
+	if t.progress != nil {
+		t.progress(t.n, t.total)
+	}
+
+	return n, err
+}
+
+// bandwidthBucket returns a token bucket for bytesPerSec, nil if unlimited.
+func bandwidthBucket(bytesPerSec float64) *TokenBucket {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+
+	return NewTokenBucket(bytesPerSec, min(int(bytesPerSec), maxTransferChunk))
+}
+
+func budgets(buckets ...*TokenBucket) []*TokenBucket {
+	var set []*TokenBucket
+
+	for _, b := range buckets {
+		if b != nil {
+			set = append(set, b)
+		}
+	}
+
+	return set
+}
+
+func contentLength(n int64) int64 {
+	if n <= 0 {
+		return -1
+	}
+
+	return n
+}
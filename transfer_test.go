@@ -0,0 +1,130 @@
+package rip
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func setupTransferServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Write(b) //nolint: errcheck
+	}))
+}
+
+func TestTransferProgress(t *testing.T) {
+	server := setupTransferServer()
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	data := bytes.Repeat([]byte("a"), 100<<10)
+
+	var up, upTotal, down int64
+
+	res, err := c.NR().
+		SetBody(io.NopCloser(bytes.NewReader(data))).
+		SetContentLength(int64(len(data))).
+		SetUploadProgress(func(sent, total int64) { up, upTotal = sent, total }).
+		SetDownloadProgress(func(read, _ int64) { down = read }).
+		Execute(t.Context(), http.MethodPost, "/")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if len(res.Body()) != len(data) {
+		t.Errorf("expected %d bytes echoed, got: %d", len(data), len(res.Body()))
+	}
+
+	if up != int64(len(data)) || upTotal != int64(len(data)) {
+		t.Errorf("expected upload progress %d/%d, got: %d/%d", len(data), len(data), up, upTotal)
+	}
+
+	if down != int64(len(data)) {
+		t.Errorf("expected download progress %d, got: %d", len(data), down)
+	}
+}
+
+func TestTransferChunk(t *testing.T) {
+	rc := io.NopCloser(bytes.NewReader(make([]byte, 8<<10)))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	tr := newTransferReader(ctx, rc, budgets(bandwidthBucket(4<<10)), nil, -1)
+
+	n, err := tr.Read(make([]byte, 32<<10))
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+
+	if n != 4<<10 {
+		t.Errorf("expected read of the bucket burst, got: %d bytes", n)
+	}
+}
+
+func TestTransferLimit(t *testing.T) {
+	type tcase struct {
+		options []Option
+		upload  float64
+		expMin  time.Duration
+	}
+
+	// 64KiB at 64KiB/s with a burst of 32KiB takes at least 0.5s.
+	tests := map[string]tcase{
+		"request upload limit": {
+			upload: 64 << 10,
+			expMin: 400 * time.Millisecond,
+		},
+		"client bandwidth budget": {
+			options: []Option{WithBandwidthLimit(0, 64<<10)},
+			expMin:  400 * time.Millisecond,
+		},
+		"unlimited": {},
+	}
+
+	server := setupTransferServer()
+	defer server.Close()
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+			c, err := NewClient(server.URL, tc.options...)
+			if err != nil {
+				t.Fatal("could not initialize client")
+			}
+
+			start := time.Now()
+
+			res, err := c.NR().
+				SetBody(bytes.NewReader(bytes.Repeat([]byte("a"), 64<<10))).
+				SetUploadLimit(tc.upload).
+				Execute(t.Context(), http.MethodPost, "/")
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+			defer res.Close()
+
+			if len(res.Body()) != 64<<10 {
+				t.Errorf("expected full body, got: %d", len(res.Body()))
+			}
+
+			if elapsed := time.Since(start); elapsed < tc.expMin {
+				t.Errorf("expected transfer to take at least %v, took: %v", tc.expMin, elapsed)
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
@@ -0,0 +1,80 @@
+package rip
+
+import (
+	"net/url"
+	"strings"
+)
+
+// URLMode controls how the request path is joined with the base URL.
+type URLMode int
+
+const (
+	// URLAppend appends the path to the base path:
+	// https://api.x.io/v1/ + /users = https://api.x.io/v1/users
+	URLAppend URLMode = iota
+	// URLResolve resolves the path as RFC 3986 reference against the base:
+	// https://api.x.io/v1/ + /users = https://api.x.io/users
+	// https://api.x.io/v1/ + users = https://api.x.io/v1/users
+	URLResolve
+)
+
+// WithURLMode sets how request paths are joined with the base URL.
+func WithURLMode(mode URLMode) Option {
+	return func(c *Client) {
+		c.options.URLMode = mode
+	}
+}
+
+// joinURL joins base and path. Absolute URLs passed as path are used as
+// is, e.g. to follow pagination links.
+func joinURL(base *url.URL, path string, mode URLMode) (*url.URL, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.IsAbs() {
+		return ref, nil
+	}
+
+	if mode == URLResolve {
+		return base.ResolveReference(ref), nil
+	}
+
+	u := *base
+	u.Fragment = ref.Fragment
+	u.RawFragment = ref.RawFragment
+
+	if ref.RawQuery != "" {
+		u.RawQuery = strings.TrimPrefix(u.RawQuery+"&"+ref.RawQuery, "&")
+	}
+
+	if ref.Path == "" {
+		return &u, nil
+	}
+
+	escaped := strings.TrimSuffix(base.EscapedPath(), "/") + "/" + strings.TrimPrefix(ref.EscapedPath(), "/")
+
+	u.Path, err = url.PathUnescape(escaped)
+	if err != nil {
+		return nil, err
+	}
+
+	u.RawPath = escaped
+
+	return &u, nil
+}
+
+// mergeQuery sets the values of query on u, keeping other parameters of u.
+func mergeQuery(u *url.URL, query url.Values) {
+	if len(query) == 0 {
+		return
+	}
+
+	q := u.Query()
+	for k, vs := range query {
+		q[k] = vs
+	}
+
+	u.RawQuery = q.Encode()
+}
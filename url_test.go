@@ -0,0 +1,116 @@
+package rip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestJoinURL(t *testing.T) {
+	type tcase struct {
+		base     string
+		path     string
+		mode     URLMode
+		expected string
+	}
+
+	tests := map[string]tcase{
+		"host only": {
+			base:     "https://api.x.io",
+			path:     "/users",
+			expected: "https://api.x.io/users",
+		},
+		"base path with trailing slash": {
+			base:     "https://api.x.io/v1/",
+			path:     "/users",
+			expected: "https://api.x.io/v1/users",
+		},
+		"base path without trailing slash": {
+			base:     "https://api.x.io/v1",
+			path:     "users",
+			expected: "https://api.x.io/v1/users",
+		},
+		"empty path": {
+			base:     "https://api.x.io/v1",
+			path:     "",
+			expected: "https://api.x.io/v1",
+		},
+		"base query": {
+			base:     "https://api.x.io/v1?key=secret",
+			path:     "/users?page=2",
+			expected: "https://api.x.io/v1/users?key=secret&page=2",
+		},
+		"escaped path": {
+			base:     "https://api.x.io/v1/",
+			path:     "/users/a%2Fb",
+			expected: "https://api.x.io/v1/users/a%2Fb",
+		},
+		"absolute url": {
+			base:     "https://api.x.io/v1/",
+			path:     "https://other.x.io/users?cursor=abc",
+			expected: "https://other.x.io/users?cursor=abc",
+		},
+		"resolve absolute path": {
+			base:     "https://api.x.io/v1/",
+			path:     "/users",
+			mode:     URLResolve,
+			expected: "https://api.x.io/users",
+		},
+		"resolve relative path": {
+			base:     "https://api.x.io/v1/",
+			path:     "users",
+			mode:     URLResolve,
+			expected: "https://api.x.io/v1/users",
+		},
+	}
+
+	fn := func(tc tcase) func(*testing.T) {
+		return func(t *testing.T) {
+			t.Helper()
+
+			base, err := url.Parse(tc.base)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+
+			got, err := joinURL(base, tc.path, tc.mode)
+			if err != nil {
+				t.Fatalf("expected err to be nil, got: %v", err)
+			}
+
+			if got.String() != tc.expected {
+				t.Errorf("expected: %v, got: %v", tc.expected, got.String())
+			}
+		}
+	}
+
+	for name, tc := range tests {
+		t.Run(name, fn(tc))
+	}
+}
+
+func TestBaseQueryMergedWithQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(r.URL.RawQuery))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL + "/v1/?key=secret&page=1")
+	if err != nil {
+		t.Fatal("could not initialize client")
+	}
+
+	res, err := c.NR().
+		SetQuery(Query{"page": 2}).
+		Execute(t.Context(), http.MethodGet, "/users")
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %v", err)
+	}
+	defer res.Close()
+
+	if got := res.String(); got != "key=secret&page=2" {
+		t.Errorf("expected key=secret&page=2, got: %s", got)
+	}
+}
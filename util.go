@@ -1,7 +1,6 @@
 package rip
 
 import (
-	"encoding/json"
 	"regexp"
 )
 
@@ -17,14 +16,8 @@ func IsJSON(ct string) bool {
 	return jsonCheck.MatchString(ct)
 }
 
-// Unmarshal helper
-func Unmarshal(ct string, b []byte, d interface{}) error {
-	if IsJSON(ct) {
-		err := json.Unmarshal(b, d)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+// Unmarshal decodes b into d using the default codec for the content type ct.
+// It returns ErrUnsupportedContentType if there is none.
+func Unmarshal(ct string, b []byte, d any) error {
+	return defaultCodecs.Unmarshal(ct, b, d)
 }